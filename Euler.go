@@ -18,7 +18,7 @@ func (es *EulerSpace) track(done <-chan interface{}, p *Particle, q *Prism, w Ve
 			case <-done:
 				return
 			default:
-				vx, vy, vz := w.PointVelocity(p, q, 0.)
+				vx, vy, vz := w.PointVelocity(p, q, q.Dbdt)
 				dt := es.Ds / math.Sqrt(math.Pow(vx, 2.)+math.Pow(vy, 2.)+math.Pow(vz, 2.)) // ds/|vn|
 				p.X += vx * dt
 				p.Y += vy * dt
@@ -41,7 +41,7 @@ func (et *EulerTime) track(done <-chan interface{}, p *Particle, q *Prism, w Vel
 			case <-done:
 				return
 			default:
-				vx, vy, vz := w.PointVelocity(p, q, 0.)
+				vx, vy, vz := w.PointVelocity(p, q, q.Dbdt)
 				p.X += vx * et.Dt
 				p.Y += vy * et.Dt
 				p.Z += vz * et.Dt
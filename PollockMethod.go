@@ -1,7 +1,7 @@
 package ptrack
 
 import (
-	"log"
+	"fmt"
 	"math"
 	"math/cmplx"
 )
@@ -18,9 +18,9 @@ type PollockMethod struct {
 }
 
 // New PollockMethod constructor
-func (pm *PollockMethod) New(q *Prism, zwl complex128, Qx0, Qx1, Qy0, Qy1, Qz0, Qz1, dt float64) {
+func (pm *PollockMethod) New(q *Prism, zwl complex128, Qx0, Qx1, Qy0, Qy1, Qz0, Qz1, dt float64) error {
 	if len(q.Z) != 4 {
-		log.Fatalf("PollockMethod can only be used with rectilinear cells")
+		return fmt.Errorf("PollockMethod can only be used with rectilinear cells, %d vertices given", len(q.Z))
 	}
 
 	// cell dimensions ' xn:left, xx:right, yn:front, yx:back, zn:bottom, zx:top
@@ -50,6 +50,7 @@ func (pm *PollockMethod) New(q *Prism, zwl complex128, Qx0, Qx1, Qy0, Qy1, Qz0,
 	pm.ax = (pm.vx1 - pm.vx0) / dx
 	pm.ay = (pm.vy1 - pm.vy0) / dy
 	pm.az = (pm.vz1 - pm.vz0) / dz
+	return nil
 }
 
 // PointVelocity returns the velocity vector for a given (x,y,z) coordinate. (must only be used with rectilinear cells)
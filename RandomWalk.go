@@ -0,0 +1,150 @@
+package ptrack
+
+import (
+	"math"
+	"math/rand/v2"
+)
+
+// RandomWalk random-walk particle tracking scheme, adding a dispersive displacement to the (Euler) advective step.
+// Dispersion follows Scheidegger's tensor with equal transverse horizontal and vertical dispersivities.
+// Where dispersion coefficients change between prisms, the dispersive displacement is evaluated at a predicted
+// position, such that particles do not accumulate in low-dispersion prisms:
+// LaBolle, E.M., J. Quastel, G.E. Fogg, and J. Gravner, 2000. Diffusion processes in composite porous media and their numerical integration by random walks: Generalized stochastic differential equations with discontinuous coefficients. Water Resources Research 36(3): 651-662.
+type RandomWalk struct {
+	Dt     float64 // time step
+	AL, AT float64 // (global) longitudinal and transverse dispersivities
+	Dm     float64 // effective molecular diffusion coefficient
+	Seed   uint64  // random seed, particles are given their own stream (by input order) such that results are reproducible
+
+	al, at map[int]float64 // (optional) per-prism dispersivities
+	d      *Domain
+	vf     map[int]VelocityFielder // flow field of the current time step
+	prsms  map[int]*Prism          // prisms of the current time step
+	nmax   int                     // maximum number of steps within a prism, zero for no limit
+	rng    *rand.Rand
+}
+
+// SetPrismDispersivities sets the longitudinal and transverse dispersivities of individual prisms, overriding AL and AT
+func (rw *RandomWalk) SetPrismDispersivities(al, at map[int]float64) {
+	rw.al, rw.at = al, at
+}
+
+// SetRandomWalk has particles tracked using the random-walk scheme, irrespective of the velocity field method.
+// Set rw to nil to revert to purely advective tracking.
+func (d *Domain) SetRandomWalk(rw *RandomWalk) {
+	if rw != nil {
+		rw.d = d
+	}
+	d.rw = rw
+}
+
+// forParticle returns a copy of the random walk with its own random stream for the particle at position k of the
+// tracked batch, unique even where particles share an ID (e.g., released from the same prism)
+func (rw *RandomWalk) forParticle(k int) *RandomWalk {
+	c := *rw
+	c.rng = rand.New(rand.NewPCG(rw.Seed, uint64(k)))
+	return &c
+}
+
+// dispersivities returns the longitudinal and transverse dispersivities of prism pid
+func (rw *RandomWalk) dispersivities(pid int) (float64, float64) {
+	al, at := rw.AL, rw.AT
+	if v, ok := rw.al[pid]; ok {
+		al = v
+	}
+	if v, ok := rw.at[pid]; ok {
+		at = v
+	}
+	return al, at
+}
+
+// displacement returns the dispersive displacement for velocity v, dispersivities al and at, and standard normal deviates z
+func (rw *RandomWalk) displacement(vx, vy, vz, al, at float64, z [3]float64, dt float64) (float64, float64, float64) {
+	vm := math.Sqrt(vx*vx + vy*vy + vz*vz)
+	sl, st := math.Sqrt(2.*(al*vm+rw.Dm)*dt), math.Sqrt(2.*(at*vm+rw.Dm)*dt)
+	if vm == 0. {
+		return sl * z[0], sl * z[1], sl * z[2] // diffusion only
+	}
+
+	// orthonormal basis aligned with the velocity
+	e1 := [3]float64{vx / vm, vy / vm, vz / vm}
+	cross := func(a, b [3]float64) [3]float64 {
+		return [3]float64{a[1]*b[2] - a[2]*b[1], a[2]*b[0] - a[0]*b[2], a[0]*b[1] - a[1]*b[0]}
+	}
+	e2 := cross(e1, [3]float64{0., 0., 1.})
+	if math.Abs(e1[2]) > .9 {
+		e2 = cross(e1, [3]float64{1., 0., 0.})
+	}
+	m := math.Sqrt(e2[0]*e2[0] + e2[1]*e2[1] + e2[2]*e2[2])
+	e2 = [3]float64{e2[0] / m, e2[1] / m, e2[2] / m}
+	e3 := cross(e1, e2)
+
+	var dx [3]float64
+	for k := range 3 {
+		dx[k] = sl*z[0]*e1[k] + st*(z[1]*e2[k]+z[2]*e3[k])
+	}
+	return dx[0], dx[1], dx[2]
+}
+
+// locate returns the prism containing particle p, searching from prism pid, -1 if outside the domain
+func (rw *RandomWalk) locate(p *Particle, pid int) int {
+	if rw.d == nil {
+		return pid
+	}
+	if q, ok := rw.d.prsms[pid]; ok && q.Contains(p) {
+		return pid
+	}
+	if pids := rw.d.ParticleToPrismIDs(p, pid); len(pids) > 0 {
+		return pids[0]
+	}
+	return -1
+}
+
+// step moves particle p, located in prism p.C, by one time step
+func (rw *RandomWalk) step(p *Particle, q *Prism, w VelocityFielder) {
+	vx, vy, vz := w.PointVelocity(p, q, q.Dbdt)
+	z := [3]float64{rw.rng.NormFloat64(), rw.rng.NormFloat64(), rw.rng.NormFloat64()}
+	al, at := rw.dispersivities(p.C)
+	dx, dy, dz := rw.displacement(vx, vy, vz, al, at, z, rw.Dt)
+
+	// drift correction: dispersive displacement re-evaluated at the predicted position, using the velocity field of the prism it falls in
+	py := Particle{X: p.X + dx, Y: p.Y + dy, Z: p.Z + dz, T: p.T}
+	if j := rw.locate(&py, p.C); j >= 0 {
+		al, at = rw.dispersivities(j)
+		qj, wj := q, w
+		if j != p.C {
+			if v, ok := rw.vf[j]; ok {
+				qj, wj = rw.prsms[j], v
+			}
+		}
+		ux, uy, uz := vx, vy, vz
+		if r, _ := wj.Local(&py); r <= rmax {
+			ux, uy, uz = wj.PointVelocity(&py, qj, qj.Dbdt)
+		}
+		dx, dy, dz = rw.displacement(ux, uy, uz, al, at, z, rw.Dt)
+	}
+
+	pn := Particle{I: p.I, C: p.C, Zone: p.Zone, X: p.X + vx*rw.Dt + dx, Y: p.Y + vy*rw.Dt + dy, Z: p.Z + vz*rw.Dt + dz, T: p.T + rw.Dt}
+	if rw.locate(&pn, p.C) < 0 { // no-flux boundary: dispersive displacement rejected
+		pn.X, pn.Y, pn.Z = p.X+vx*rw.Dt, p.Y+vy*rw.Dt, p.Z+vz*rw.Dt
+	}
+	*p = pn
+}
+
+// track particle to the next time step, stopping after nmax steps (e.g., stagnant in a zero-velocity prism)
+func (rw *RandomWalk) track(done <-chan interface{}, p *Particle, q *Prism, w VelocityFielder) <-chan Particle {
+	chout := make(chan Particle)
+	go func() {
+		defer close(chout)
+		for n := 0; rw.nmax <= 0 || n < rw.nmax; n++ {
+			select {
+			case <-done:
+				return
+			default:
+				rw.step(p, q, w)
+				chout <- *p
+			}
+		}
+	}()
+	return chout
+}
@@ -0,0 +1,62 @@
+package ptrack
+
+import "testing"
+
+func TestRandomWalkSharedParticleID(t *testing.T) {
+	d := rowDomain([]float64{1., 1., 1., 1., 1., 1.}, nil)
+	if err := d.MakeVector(); err != nil {
+		t.Fatal(err)
+	}
+	d.SetRandomWalk(&RandomWalk{Dt: .01, AL: .1, AT: .01, Seed: 42})
+	ps := Particles{{I: 7, X: .5, Y: .5, Z: .5}, {I: 7, X: .5, Y: .5, Z: .5}} // e.g., released from the same prism
+	run := func() [2]Particle {
+		pl, _, _, err := d.TrackParticlesConcurrent(ps, TrackOptions{MaxTime: .5}, 2, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return [2]Particle{pl[0][len(pl[0])-1], pl[1][len(pl[1])-1]}
+	}
+	e := run()
+	if e[0].X == e[1].X && e[0].Y == e[1].Y {
+		t.Errorf("particles sharing ID %d took identical random walks, ending at (%.4f,%.4f)", ps[0].I, e[0].X, e[0].Y)
+	}
+	if run() != e {
+		t.Errorf("random walks are not reproducible")
+	}
+}
+
+func TestRandomWalkWaterlooWellCapture(t *testing.T) {
+	d := rowDomain([]float64{1., 1., .5, .5}, map[int]float64{1: -.5})
+	if err := d.SetWells([]Well{{X: 1.5, Y: .5, Z: .5, Q: -.5}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.SetWaterlooOptions(WaterlooOptions{M: 20, N: 5, Workers: 1, Progress: func(int, int) {}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.MakeWaterloo(&RungeKutta{Dt: .001}); err != nil {
+		t.Fatal(err)
+	}
+	d.SetRandomWalk(&RandomWalk{Dt: .001, AL: 1e-4, AT: 1e-5, Seed: 1})
+	_, _, term, err := d.TrackParticles(Particles{{X: .5, Y: .5, Z: .5}}, TrackOptions{}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if term[0].Status != TermWell || term[0].Prism != 1 {
+		t.Errorf("particle %s, want captured by the well at prism 1", term[0])
+	}
+}
+
+func TestRandomWalkStagnantIsLimited(t *testing.T) {
+	d := rowDomain([]float64{0., 0., 0.}, nil)
+	if err := d.MakeVector(); err != nil {
+		t.Fatal(err)
+	}
+	d.SetRandomWalk(&RandomWalk{Dt: .01, AL: .1, AT: .01, Seed: 1}) // no velocity, no diffusion: the particle never moves
+	pl, _, term, err := d.TrackParticles(Particles{{X: .5, Y: .5, Z: .5}}, TrackOptions{MaxVertices: 500}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if term[0].Status != TermMaxVertices || len(pl[0]) != 500 {
+		t.Errorf("particle %s after %d vertices, want to reach the 500 vertex limit", term[0], len(pl[0]))
+	}
+}
@@ -82,34 +82,34 @@ func trial(p *Particle, q *Prism, w VelocityFielder, dt float64) bool {
 	if r, _ := w.Local(p); r > rmax {
 		return true
 	}
-	vx, vy, vz := w.PointVelocity(p, q, 0.)
+	vx, vy, vz := w.PointVelocity(p, q, q.Dbdt)
 	k1 := dt * vx
 	l1 := dt * vy
 	m1 := dt * vz
 
-	p2 := Particle{0, 0, p.X + k1/2., p.Y + l1/2., p.Z + m1/2., p.T + dt/2.}
+	p2 := Particle{X: p.X + k1/2., Y: p.Y + l1/2., Z: p.Z + m1/2., T: p.T + dt/2.}
 	if r, _ := w.Local(&p2); r > rmax {
 		return true
 	}
-	vx, vy, vz = w.PointVelocity(&p2, q, 0.)
+	vx, vy, vz = w.PointVelocity(&p2, q, q.Dbdt)
 	k2 := dt * vx
 	l2 := dt * vy
 	m2 := dt * vz
 
-	p3 := Particle{0, 0, p.X + k2/2., p.Y + l2/2., p.Z + m2/2., p.T + dt/2.}
+	p3 := Particle{X: p.X + k2/2., Y: p.Y + l2/2., Z: p.Z + m2/2., T: p.T + dt/2.}
 	if r, _ := w.Local(&p3); r > rmax {
 		return true
 	}
-	vx, vy, vz = w.PointVelocity(&p3, q, 0.)
+	vx, vy, vz = w.PointVelocity(&p3, q, q.Dbdt)
 	k3 := dt * vx
 	l3 := dt * vy
 	m3 := dt * vz
 
-	p4 := Particle{0, 0, p.X + k3, p.Y + l3, p.Z + m3, p.T + dt}
+	p4 := Particle{X: p.X + k3, Y: p.Y + l3, Z: p.Z + m3, T: p.T + dt}
 	if r, _ := w.Local(&p4); r > rmax {
 		return true
 	}
-	vx, vy, vz = w.PointVelocity(&p4, q, 0.)
+	vx, vy, vz = w.PointVelocity(&p4, q, q.Dbdt)
 	k4 := dt * vx
 	l4 := dt * vy
 	m4 := dt * vz
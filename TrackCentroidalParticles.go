@@ -1,15 +1,15 @@
 package ptrack
 
 // Track a collection of particles through the centroid of at least 1 model cell
-func (d *Domain) TrackCentroidalParticlesReverse(prnt bool) ([][]Particle, int, []int) {
-	o, pxr, c, k := make([][]Particle, d.Nprism()), make([]int, d.Nprism()), 0, 0
+func (d *Domain) TrackCentroidalParticlesReverse(prnt bool) ([][]Particle, int, []int, []Termination) {
+	ps, pxr, k := make([]*Particle, d.Nprism()), make([]int, d.Nprism()), 0
 	d.ReverseVectorField()
 	for pid, p := range d.prsms {
-		o[k] = d.trackParticle(p.CentroidParticle(pid), pid, prnt)
+		ps[k] = p.CentroidParticle(pid)
 		pxr[k] = pid
-		c += len(o[k])
 		k++
 	}
+	o, c, term := d.trackBatch(ps, pxr, TrackOptions{}, 1, prnt)
 
 	// // reverse tracks
 	// d.ReverseVectorField()
@@ -22,5 +22,5 @@ func (d *Domain) TrackCentroidalParticlesReverse(prnt bool) ([][]Particle, int,
 	// 	o[k] = append(ar, o[k]...)
 	// }
 
-	return o, c, pxr
+	return o, c, pxr, term
 }
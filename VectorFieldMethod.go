@@ -1,6 +1,7 @@
 package ptrack
 
 import (
+	"fmt"
 	"math"
 	"math/cmplx"
 
@@ -13,7 +14,7 @@ type VectorMethSoln struct {
 	r, vx, vy, vzt, vzb float64
 }
 
-func (vm *VectorMethSoln) New(zc complex128, q []float64, por, r float64) { // left-up-right-down-bottom-top
+func (vm *VectorMethSoln) New(zc complex128, q []float64, por, r float64) error { // left-up-right-down-bottom-top
 	vm.zc = zc
 	vm.r = r
 	switch len(q) {
@@ -28,8 +29,9 @@ func (vm *VectorMethSoln) New(zc complex128, q []float64, por, r float64) { // l
 		vm.vzb = q[4] / por
 		vm.vzt = -q[5] / por
 	default:
-		panic("New VectorMethSoln error")
+		return fmt.Errorf("New VectorMethSoln error: %d fluxes given, expecting 3 (xyz) or 6 (left-up-right-down-bottom-top)", len(q))
 	}
+	return nil
 }
 
 // PointVelocity returns the velocity vector for a given (x,y,z) coordinate
@@ -2,7 +2,6 @@ package ptrack
 
 import (
 	"fmt"
-	"log"
 	"math"
 	"math/cmplx"
 
@@ -13,47 +12,114 @@ import (
 // WatMethSoln (The Waterloo Method Solution): is a struct that contains a grid cell's internal flow field
 // Ramadhan, M., 2015, A Semi-Analytical Particle Tracking Algorithm for Arbitrary Unstructured Grids. Unpublished MASc. Thesis. University of Waterloo, Waterloo Ontario.
 type WatMethSoln struct {
-	aT, zwl        []complex128
-	zc, r          complex128
-	qv, qw, ql, qb float64
-	m, n, nf       int // n control points; order of approximiation
+	aT, zwl    []complex128 // Taylor coefficients; local well coordinates
+	qwl        []float64    // well strengths (Q/2π)
+	zc, r      complex128
+	qv, ql, qb float64
+	err        float64 // perimeter flux error (see perimeterError)
+	unmet      bool    // adaptive construction did not reach its target error (see NewAdaptive)
+	m, n, nf   int     // n control points; order of approximiation
 }
 
-// New WatMethSoln constructor
-func (w *WatMethSoln) New(prismID int, p *Prism, Qj []float64, zw complex128, Qtop, Qbot, Qwell float64, m, n int, prnt bool) {
+// New WatMethSoln constructor, wells zw are point sources/sinks at their coordinates within the prism, off its perimeter
+func (w *WatMethSoln) New(prismID int, p *Prism, Qj []float64, zw []Well, Qtop, Qbot float64, m, n int, prnt bool) error {
 	w.m = m      // Total control points
 	w.n = n      // Order of Approximation
 	if m < 2*n { // constraint
-		log.Fatal("control point specification error: m < 2*n")
+		return fmt.Errorf("control point specification error in prism %d: m < 2*n (m=%d, n=%d)", prismID, m, n)
+	}
+	if len(Qj) != len(p.Z) {
+		return fmt.Errorf("prism %d given %d lateral fluxes for %d faces", prismID, len(Qj), len(p.Z))
 	}
 	w.ql = 0.
 	w.nf = len(p.Z) // n faces
-	w.zwl = make([]complex128, 1)
-	wbal, Qvert := 0., Qtop-Qbot
+	wbal, Qvert, Qwell := 0., Qtop-Qbot, 0.
+	for _, wl := range zw {
+		Qwell += wl.Q
+	}
 	for j := 0; j < w.nf; j++ {
 		wbal += Qj[j] // flux along face j; positive in, negative out (left-up-right-down)
 		w.zc += p.Z[j]
 	}
 	w.ql = wbal // cumulative lateral inflows
-	wbal += Qwell - Qvert
+	wbal += Qwell + p.Qss - Qvert
 	if math.Abs(wbal)/w.ql > mingtzero { // step 1: check mass balance (eq 3.7)
-		fmt.Printf("Qwell: %6.3f  Qbot: %6.3f  Qtop: %6.3f  Qlat %6.3f\n", Qwell, Qbot, Qtop, Qj)
-		log.Fatalf("cell mass-balance error in prism %d: %v\n", prismID, wbal)
+		return fmt.Errorf("cell mass-balance error in prism %d: %v (Qwell: %6.3f  Qsto: %6.3f  Qbot: %6.3f  Qtop: %6.3f  Qlat %6.3f)", prismID, wbal, Qwell, p.Qss, Qbot, Qtop, Qj)
 	}
 	w.zc /= complex(float64(w.nf), 0.) // cell centroid
-	w.qv = Qvert / p.Area              // Qvert = Qtop-Qbot, positive up, negative down
-	w.qw = Qwell / 2. / math.Pi
+	w.qv = (Qvert - p.Qss) / p.Area    // Qvert = Qtop-Qbot, positive up, negative down; less storage released uniformly over the prism
+	w.ql += p.Qss                      // vertical velocity accumulates lateral inflows and storage with depth (eq. 3.18)
+	w.zwl, w.qwl = make([]complex128, 0, len(zw)), make([]float64, 0, len(zw))
+	for k, wl := range zw {
+		if math.IsNaN(wl.X) || math.IsNaN(wl.Y) {
+			return fmt.Errorf("need to specify coordinate of well %d in prism %d", k, prismID)
+		}
+		if !p.ContainsXY(wl.X, wl.Y) {
+			return fmt.Errorf("well %d (%.3f,%.3f) not within prism %d", k, wl.X, wl.Y, prismID)
+		}
+		if wl.Q == 0. {
+			continue
+		}
+		if perimeterDistance(p.Z, complex(wl.X, wl.Y)) <= tol {
+			return fmt.Errorf("well %d (%.3f,%.3f) lies on the perimeter of prism %d", k, wl.X, wl.Y, prismID) // singular at the control points (eq. 3.11b)
+		}
+		w.zwl = append(w.zwl, complex(wl.X, wl.Y)) // converted to local coordinates once r is known
+		w.qwl = append(w.qwl, wl.Q/2./math.Pi)
+	}
 
-	w.buildCoefTaylor(p.Z, Qj, prnt) // (p.Z, Qj, Qvert == 0. && Qwell == 0.)
+	if err := w.buildCoefTaylor(p.Z, Qj, prnt); err != nil { // (p.Z, Qj, Qvert == 0. && Qwell == 0.)
+		return fmt.Errorf("prism %d: %w", prismID, err)
+	}
 
 	w.qb = Qbot / p.Area
-	w.zwl[0] = (zw - w.zc) / w.r // local well coordinate
-	if cmplx.IsNaN(zw) && Qwell != 0. {
-		log.Panicln("need to specify well coordinate")
+	w.err = w.perimeterError(p.Z, Qj, 2*m)
+	return nil
+}
+
+// perimeterDistance returns the shortest distance from point c to the perimeter of polygon z
+func perimeterDistance(z []complex128, c complex128) float64 {
+	dx := math.MaxFloat64
+	for j := range z {
+		a, d := z[j], z[(j+1)%len(z)]-z[j]
+		s := 0.
+		if l2 := real(d)*real(d) + imag(d)*imag(d); l2 > 0. {
+			s = math.Max(0., math.Min(1., (real(c-a)*real(d)+imag(c-a)*imag(d))/l2)) // projection onto the face
+		}
+		dx = math.Min(dx, cmplx.Abs(c-a-complex(s, 0.)*d))
+	}
+	return dx
+}
+
+// NewAdaptive WatMethSoln constructor, increasing the order of approximation n (trying m = 3n and 4n control points)
+// until the perimeter flux error is within etol, up to order nmax. When etol is not met, the most accurate solution is kept
+// and flagged as such (see WaterlooAccuracy).
+func (w *WatMethSoln) NewAdaptive(prismID int, p *Prism, Qj []float64, zw []Well, Qtop, Qbot, etol float64, nmax int) error {
+	const n0, dn = 5, 5
+	var best WatMethSoln
+	for n := n0; n <= max(nmax, n0); n += dn {
+		for _, m := range []int{3 * n, 4 * n} {
+			var c WatMethSoln
+			if err := c.New(prismID, p, Qj, zw, Qtop, Qbot, m, n, false); err != nil {
+				return err
+			}
+			if best.aT == nil || c.err < best.err {
+				best = c
+			}
+			if c.err <= etol {
+				*w = best
+				return nil
+			}
+		}
 	}
+	*w = best
+	w.unmet = true
+	return nil
 }
 
-func (w *WatMethSoln) buildCoefTaylor(zj []complex128, qj []float64, prnt bool) {
+// Accuracy returns the number of control points, the order of approximation and the resulting perimeter flux error
+func (w *WatMethSoln) Accuracy() (m, n int, err float64) { return w.m, w.n, w.err }
+
+func (w *WatMethSoln) buildCoefTaylor(zj []complex128, qj []float64, prnt bool) error {
 	// The Waterloo method
 	// Muhammad Ramadhan, 2015. a Semi-Analytic Particle Tracking Algorithm for Arbitrary Unstructured Grids. M.A.Sc thesis. University of Waterloo.
 
@@ -68,6 +134,9 @@ func (w *WatMethSoln) buildCoefTaylor(zj []complex128, qj []float64, prnt bool)
 		alpha[j] = cmplx.Phase(complex(imag(d), -real(d))) // angle for each side of the polygon calculated in radian (table 3.6)
 	}
 	w.r = complex(r, 0.)
+	for k, z := range w.zwl {
+		w.zwl[k] = (z - w.zc) / w.r // local well coordinate
+	}
 
 	// step 3: build control points
 	sCtrl, zCtrl, ijx := make([]float64, w.m), make([]complex128, w.m), make([]int, w.m)
@@ -91,12 +160,15 @@ func (w *WatMethSoln) buildCoefTaylor(zj []complex128, qj []float64, prnt bool)
 	// step 4: determine normalized cell flows: Qcell, qVert and qWell, and build Qtaylor
 	qTaylorni := make([]float64, w.m)
 	for i := 0; i < w.m; i++ {
-		j := ijx[i]                                                        // side ith control point on the jth face
-		ca := complex(math.Cos(alpha[j]), math.Sin(alpha[j]))              // complex angle
-		qCellni := qj[j] / lj[j]                                           // eq. 3.8 normalized cell flows
-		qVertni := real(complex(-w.qv*r*real(zCtrl[i]), 0.) * ca)          // eq. 3.11a
-		qWellni := real(-ca * complex(w.qw/r, 0.) / (zCtrl[i] - w.zwl[0])) // eq. 3.11b
-		qTaylorni[i] = qCellni - qVertni - qWellni                         // eq. 3.10
+		j := ijx[i]                                               // side ith control point on the jth face
+		ca := complex(math.Cos(alpha[j]), math.Sin(alpha[j]))     // complex angle
+		qCellni := qj[j] / lj[j]                                  // eq. 3.8 normalized cell flows
+		qVertni := real(complex(-w.qv*r*real(zCtrl[i]), 0.) * ca) // eq. 3.11a
+		qWellni := 0.
+		for k, zwl := range w.zwl {
+			qWellni += real(ca * complex(w.qwl[k]/r, 0.) / (zCtrl[i] - zwl)) // eq. 3.11b, superposed (inward normal discharge, consistent with cmplxVelWell)
+		}
+		qTaylorni[i] = qCellni - qVertni - qWellni // eq. 3.10
 	}
 
 	// step 5: build Phi_Taylor
@@ -118,7 +190,10 @@ func (w *WatMethSoln) buildCoefTaylor(zj []complex128, qj []float64, prnt bool)
 	}
 	psiU := mat.NewDense(w.m, 2*w.n, psiTaylorU)
 	psiT := mat.NewVecDense(w.m, psiTaylor)
-	aTaylorV := svdSolve(psiU, psiT)
+	aTaylorV, err := svdSolve(psiU, psiT)
+	if err != nil {
+		return err
+	}
 
 	aTaylor := make([]complex128, w.n)
 	for j := 0; j < w.n; j++ {
@@ -129,6 +204,7 @@ func (w *WatMethSoln) buildCoefTaylor(zj []complex128, qj []float64, prnt bool)
 	if prnt {
 		w.plotPerimeterFlux(zj, qj, lj, p, 500) //, w.m) //, 500) //
 	}
+	return nil
 }
 
 // PointVelocity returns the velocity vector for a given (x,y,z) coordinate. ** Set dbdt = 0. for steady-state cases
@@ -140,8 +216,10 @@ func (w *WatMethSoln) PointVelocity(p *Particle, q *Prism, dbdt float64) (float6
 	if w.qv != 0. {
 		o += w.cmplxVelVert(zl)
 	}
-	if w.qw != 0. && zl != 0 {
-		o += w.cmplxVelWell(zl, 0)
+	for k, zwl := range w.zwl {
+		if zl != zwl {
+			o += w.cmplxVelWell(zl, k)
+		}
 	}
 	// vz := (w.qb + (p.Z-q.Bot)*w.ql/q.Area/bl) / q.Por     // eq. 3.19 (steady-state case)
 	vz := (w.qb + (p.Z-q.Bot)*w.ql/q.Area/bz) / q.Por // eq. 3.18 (transient case)
@@ -151,6 +229,17 @@ func (w *WatMethSoln) PointVelocity(p *Particle, q *Prism, dbdt float64) (float6
 	return vx, vy, vz // eq. 3.17
 }
 
+// nearWell returns the index of the well within capture distance (wellTol) of particle p, -1 if none
+func (w *WatMethSoln) nearWell(p *Particle) int {
+	zl := (complex(p.X, p.Y) - w.zc) / w.r // complex local coordinate
+	for k, zwl := range w.zwl {
+		if cmplx.Abs(zl-zwl) < wellTol/real(w.r) {
+			return k
+		}
+	}
+	return -1
+}
+
 // Local returns whether the point is solvable within the solution space
 func (w *WatMethSoln) Local(p *Particle) (float64, bool) {
 	zl := (complex(p.X, p.Y) - w.zc) / w.r // complex local coordinate
@@ -199,8 +288,8 @@ func (w *WatMethSoln) ExportComplexPotentialField(q *Prism, pointDensity int) {
 				if w.qv != 0. {
 					o += w.cmplxPotVert(zl)
 				}
-				if w.qw != 0. {
-					o += w.cmplxPotWell(zl, 0)
+				for k := range w.zwl {
+					o += w.cmplxPotWell(zl, k)
 				}
 				h, s := real(o), imag(o)
 				csvw.WriteLine(fx, fy, h, s)
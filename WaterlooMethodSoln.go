@@ -10,7 +10,7 @@ import (
 	"gonum.org/v1/gonum/mat"
 )
 
-func svdSolve(a *mat.Dense, b *mat.VecDense) *mat.VecDense {
+func svdSolve(a *mat.Dense, b *mat.VecDense) (*mat.VecDense, error) {
 	// following https://www.youtube.com/watch?v=oTCLm-WnX9Y
 	// svdSolve(mat.NewDense(3, 2, []float64{1., 0., 0., 2., 0., 1.}), mat.NewVecDense(3, []float64{0., 1., 0.}))
 	// Solve x in Ax=b
@@ -18,7 +18,7 @@ func svdSolve(a *mat.Dense, b *mat.VecDense) *mat.VecDense {
 
 	var svd mat.SVD
 	if !svd.Factorize(a, mat.SVDFull) {
-		panic("SVD solver error")
+		return nil, fmt.Errorf("SVD solver error: factorization failed")
 	}
 	u, v := &mat.Dense{}, &mat.Dense{}
 	svd.UTo(u)
@@ -41,7 +41,7 @@ func svdSolve(a *mat.Dense, b *mat.VecDense) *mat.VecDense {
 	x := mat.NewVecDense(ac, nil)
 	x.MulVec(v, y)
 
-	return x
+	return x, nil
 }
 
 func (w *WatMethSoln) cmplxPotFlow(zl complex128) complex128 {
@@ -61,7 +61,7 @@ func (w *WatMethSoln) cmplxPotVert(zl complex128) complex128 {
 }
 
 func (w *WatMethSoln) cmplxPotWell(zl complex128, wID int) complex128 {
-	h := w.qw // well function (eq. 3.9)
+	h := w.qwl[wID] // well function (eq. 3.9)
 	h *= math.Log(real(w.r) * cmplx.Abs(zl-w.zwl[wID]))
 	return complex(h, 0.)
 }
@@ -82,7 +82,7 @@ func (w *WatMethSoln) cmplxVelVert(zl complex128) complex128 {
 }
 
 func (w *WatMethSoln) cmplxVelWell(zl complex128, wID int) complex128 {
-	q := complex(-w.qw, 0.) // well function (eq. 3.16)
+	q := complex(-w.qwl[wID], 0.) // well function (eq. 3.16)
 	q /= w.r * (zl - w.zwl[wID])
 	return q
 }
@@ -129,7 +129,7 @@ func (w *WatMethSoln) plotPerimeterFlux(zj []complex128, qj, lj []float64, p flo
 	}
 	er /= float64(b) * sliceMax(qi)
 
-	fmt.Printf("average flow error: %6.4f ", er)
+	fmt.Printf("average flow error: %6.4f (stream function), %6.4f (normal discharge) ", er, w.perimeterError(zj, qj, b))
 
 	m := make(map[string][]float64)
 	m["potential"] = hi
@@ -147,6 +147,43 @@ func (w *WatMethSoln) plotPerimeterFlux(zj []complex128, qj, lj []float64, p flo
 	txtw.Close()
 }
 
+// perimeterError returns the mean absolute difference between the specified and computed normal fluxes along
+// the prism perimeter, evaluated at b points and relative to the largest specified (normalized) face flux
+func (w *WatMethSoln) perimeterError(zj []complex128, qj []float64, b int) float64 {
+	lj, p, qx := make([]float64, w.nf), 0., 0.
+	for j := 0; j < w.nf; j++ {
+		lj[j] = cmplx.Abs(zj[(j+1)%w.nf] - zj[j])
+		p += lj[j]
+		qx = math.Max(qx, math.Abs(qj[j]/lj[j]))
+	}
+	if qx == 0. || b <= 0 {
+		return 0.
+	}
+	er, pPrev, pNext, j, sc := 0., 0., lj[0], 0, p/float64(b)
+	for i := 0; i < b; i++ {
+		s := (float64(i) + 0.5) * sc
+		for s > pNext && j < w.nf-1 {
+			pPrev = pNext
+			j++
+			pNext += lj[j]
+		}
+		jnext := (j + 1) % w.nf
+		d := zj[jnext] - zj[j]
+		zl := (complex((s-pPrev)/lj[j], 0.)*d + zj[j] - w.zc) / w.r
+		o := w.cmplxVelFlow(zl)
+		if w.qv != 0. {
+			o += w.cmplxVelVert(zl)
+		}
+		for k := range w.zwl {
+			o += w.cmplxVelWell(zl, k)
+		}
+		ca := complex(imag(d), -real(d)) / complex(lj[j], 0.) // unit inward normal (vertices clockwise)
+		qn := -real(o)*real(ca) + imag(o)*imag(ca)            // inward normal discharge
+		er += math.Abs(qj[j]/lj[j] - qn)
+	}
+	return er / float64(b) / qx
+}
+
 func sliceMax(s []float64) float64 {
 	x := -math.MaxFloat64
 	for _, v := range s {
@@ -0,0 +1,34 @@
+package ptrack
+
+import "testing"
+
+func TestWaterlooWellPerimeter(t *testing.T) {
+	var p Prism
+	z := []complex128{0, 10i, 10 + 10i, 10} // clockwise
+	if err := p.New(z, 1., 0., 1., 0., .3); err != nil {
+		t.Fatal(err)
+	}
+	qj := []float64{1., 0., 0., 0.} // all inflow from the left, captured by the well
+	var w WatMethSoln
+	if err := w.New(0, &p, qj, []Well{{X: 4., Y: 6., Q: -1.}}, 0., 0., 60, 20, false); err != nil {
+		t.Fatal(err)
+	}
+	if er := w.perimeterError(z, qj, 200); er > .05 {
+		t.Errorf("perimeter flux error %.3f with a single well, expecting < 0.05", er)
+	}
+}
+
+func TestWaterlooWellOnPerimeter(t *testing.T) {
+	var p Prism
+	z := []complex128{0, 10i, 10 + 10i, 10} // clockwise
+	if err := p.New(z, 1., 0., 1., 0., .3); err != nil {
+		t.Fatal(err)
+	}
+	qj := []float64{1., 0., 0., 0.}
+	for _, wl := range []Well{{X: 0., Y: 5., Q: -1.}, {X: 10., Y: 10., Q: -1.}} { // face, vertex
+		var w WatMethSoln
+		if err := w.New(0, &p, qj, []Well{wl}, 0., 0., 60, 20, false); err == nil {
+			t.Errorf("well at (%g,%g): expected an error for a well on the prism perimeter", wl.X, wl.Y)
+		}
+	}
+}
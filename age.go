@@ -0,0 +1,64 @@
+package ptrack
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/maseology/mmio"
+)
+
+// PrismAge is the travel time of water through a prism centroid
+type PrismAge struct {
+	Age            float64 // backward travel time, from recharge
+	LifeExpectancy float64 // forward travel time, to discharge
+}
+
+// TransitTime returns the total travel time, from recharge to discharge
+func (a PrismAge) TransitTime() float64 { return a.Age + a.LifeExpectancy }
+
+// CentroidalAges returns the age and life expectancy of every prism, from pathlines pl and prism IDs pxr as returned from TrackCentroidalParticles.
+// Pathlines are joined at the centroid, backward-tracked vertices having negative times.
+// Ages of pathlines stopped by TrackOptions are underestimated.
+func CentroidalAges(pl [][]Particle, pxr []int) (map[int]PrismAge, error) {
+	if len(pl) != len(pxr) {
+		return nil, fmt.Errorf("CentroidalAges: %d pathlines given for %d prisms", len(pl), len(pxr))
+	}
+	o := make(map[int]PrismAge, len(pl))
+	for k, a := range pl {
+		if len(a) == 0 {
+			continue
+		}
+		o[pxr[k]] = PrismAge{Age: math.Max(-a[0].T, 0.), LifeExpectancy: math.Max(a[len(a)-1].T, 0.)}
+	}
+	return o, nil
+}
+
+// ExportVTKages saves model domain as a *.vtk file, with prism age, life expectancy and transit time as cell data
+func (d *Domain) ExportVTKages(filepath string, vertExag float64, ages map[int]PrismAge) error {
+	fmt.Println(" exporting VTK prism ages..")
+	sc := []vtkScalars{{"age", make(map[int]float64, len(ages))}, {"lifeExpectancy", make(map[int]float64, len(ages))}, {"transitTime", make(map[int]float64, len(ages))}}
+	for i, a := range ages {
+		sc[0].V[i], sc[1].V[i], sc[2].V[i] = a.Age, a.LifeExpectancy, a.TransitTime()
+	}
+	if err := d.writeVTK(filepath, vertExag, sc); err != nil {
+		return fmt.Errorf("ExportVTKages: %w", err)
+	}
+	return nil
+}
+
+// SaveAgesCSV saves prism age, life expectancy and transit time
+func SaveAgesCSV(fp string, ages map[int]PrismAge) {
+	pids := make([]int, 0, len(ages))
+	for i := range ages {
+		pids = append(pids, i)
+	}
+	sort.Ints(pids)
+	csvw := mmio.NewCSVwriter(fp)
+	csvw.WriteHead("pid,age,lifeExpectancy,transitTime")
+	for _, i := range pids {
+		a := ages[i]
+		csvw.WriteLine(i, a.Age, a.LifeExpectancy, a.TransitTime())
+	}
+	csvw.Close()
+}
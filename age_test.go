@@ -0,0 +1,39 @@
+package ptrack
+
+import (
+	"math"
+	"testing"
+)
+
+func TestCentroidalAgesRow(t *testing.T) {
+	d := rowDomain([]float64{1., 1., 1., 1., 1., 1., 1.}, nil)
+	if err := d.MakeVector(); err != nil {
+		t.Fatal(err)
+	}
+	pl, _, pxr, _ := d.TrackCentroidalParticles(nil, TrackOptions{}, false)
+	ages, err := CentroidalAges(pl, pxr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ages) != 6 {
+		t.Fatalf("%d ages, want one per prism", len(ages))
+	}
+
+	// prisms are crossed in .3, vector pathlines ending upon entering the first and last prisms of the row
+	for i := 1; i < 5; i++ {
+		a := ages[i]
+		if math.Abs(a.Age-(float64(i)-.5)*.3) > 1e-4 || math.Abs(a.LifeExpectancy-(4.5-float64(i))*.3) > 1e-4 {
+			t.Errorf("prism %d: age %g and life expectancy %g, want %g and %g", i, a.Age, a.LifeExpectancy, (float64(i)-.5)*.3, (4.5-float64(i))*.3)
+		}
+		if math.Abs(a.TransitTime()-1.2) > 1e-4 {
+			t.Errorf("prism %d: transit time %g, want 1.2", i, a.TransitTime())
+		}
+	}
+	if ages[0].Age != 0. || ages[5].LifeExpectancy != 0. {
+		t.Errorf("age of the upstream prism %g and life expectancy of the downstream prism %g, want 0", ages[0].Age, ages[5].LifeExpectancy)
+	}
+
+	if _, err := CentroidalAges(pl, pxr[1:]); err == nil {
+		t.Error("expected an error for mismatched prism IDs")
+	}
+}
@@ -0,0 +1,196 @@
+package analysis
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/maseology/mmio"
+	"github.com/maseology/ptrack"
+)
+
+// Receptor is a set of prisms (e.g., wells, constant-head boundaries) and/or zones at which particle arrivals are recorded
+type Receptor struct {
+	Name   string
+	Prisms map[int]bool
+	Zones  map[int]bool
+}
+
+// PrismReceptor returns a receptor made of prisms pids
+func PrismReceptor(name string, pids ...int) Receptor {
+	r := Receptor{Name: name, Prisms: make(map[int]bool, len(pids))}
+	for _, i := range pids {
+		r.Prisms[i] = true
+	}
+	return r
+}
+
+// ZoneReceptor returns a receptor made of the prisms belonging to zones zns (see ptrack.Domain.SetZones)
+func ZoneReceptor(name string, zns ...int) Receptor {
+	r := Receptor{Name: name, Zones: make(map[int]bool, len(zns))}
+	for _, z := range zns {
+		r.Zones[z] = true
+	}
+	return r
+}
+
+func (r *Receptor) contains(p *ptrack.Particle) bool {
+	return r.Prisms[p.C] || r.Zones[p.Zone]
+}
+
+// Arrival of a particle at a receptor
+type Arrival struct {
+	PID  int     // pathline index
+	T, W float64 // travel time and weight
+}
+
+// Breakthrough is the travel-time distribution of particles arriving at a receptor
+type Breakthrough struct {
+	Receptor                  string
+	Arrivals                  []Arrival // sorted by travel time
+	W                         float64   // total weight of arrivals
+	Mean, Var, Skew, Min, Max float64   // (weighted) travel-time moments
+}
+
+// StartWeights returns the weight of every pathline given the weight of its starting prism, e.g., prism flux or volume
+func StartWeights(pl [][]ptrack.Particle, w map[int]float64) []float64 {
+	o := make([]float64, len(pl))
+	for k, a := range pl {
+		if len(a) > 0 {
+			o[k] = w[a[0].C]
+		}
+	}
+	return o
+}
+
+// Breakthroughs returns the breakthrough at every receptor of pathlines pl, as returned from tracking or loaded using ptrack.LoadPathlinesGOB.
+// A particle arrives at the first vertex located within the receptor; travel time is measured from its first vertex.
+// Weights w are given per pathline, set to nil for unit weights.
+func Breakthroughs(pl [][]ptrack.Particle, rs []Receptor, w []float64) ([]*Breakthrough, error) {
+	if w != nil && len(w) != len(pl) {
+		return nil, fmt.Errorf("Breakthroughs: %d weights given for %d pathlines", len(w), len(pl))
+	}
+	o := make([]*Breakthrough, len(rs))
+	for j := range rs {
+		b := Breakthrough{Receptor: rs[j].Name}
+		for k, a := range pl {
+			wk := 1.
+			if w != nil {
+				wk = w[k]
+			}
+			if wk <= 0. || len(a) == 0 {
+				continue
+			}
+			for _, p := range a {
+				if rs[j].contains(&p) {
+					b.Arrivals = append(b.Arrivals, Arrival{PID: k, T: p.T - a[0].T, W: wk})
+					break
+				}
+			}
+		}
+		b.summarize()
+		o[j] = &b
+	}
+	return o, nil
+}
+
+func (b *Breakthrough) summarize() {
+	sort.SliceStable(b.Arrivals, func(i, j int) bool { return b.Arrivals[i].T < b.Arrivals[j].T })
+	b.W, b.Mean, b.Var, b.Skew, b.Min, b.Max = 0., math.NaN(), math.NaN(), math.NaN(), math.NaN(), math.NaN()
+	if len(b.Arrivals) == 0 {
+		return
+	}
+	s := 0.
+	for _, a := range b.Arrivals {
+		b.W += a.W
+		s += a.W * a.T
+	}
+	b.Mean = s / b.W
+	m2, m3 := 0., 0.
+	for _, a := range b.Arrivals {
+		d := a.T - b.Mean
+		m2 += a.W * d * d
+		m3 += a.W * d * d * d
+	}
+	b.Var = m2 / b.W
+	if b.Var > 0. {
+		b.Skew = m3 / b.W / math.Pow(b.Var, 1.5)
+	}
+	b.Min, b.Max = b.Arrivals[0].T, b.Arrivals[len(b.Arrivals)-1].T
+}
+
+// CDF returns the (weighted) cumulative distribution of travel times
+func (b *Breakthrough) CDF() (t, f []float64) {
+	t, f = make([]float64, len(b.Arrivals)), make([]float64, len(b.Arrivals))
+	c := 0.
+	for i, a := range b.Arrivals {
+		c += a.W
+		t[i], f[i] = a.T, c/b.W
+	}
+	return
+}
+
+// Percentile returns the (weighted) q-th travel-time quantile, q in [0,1]
+func (b *Breakthrough) Percentile(q float64) float64 {
+	if len(b.Arrivals) == 0 {
+		return math.NaN()
+	}
+	t, f := b.CDF()
+	i := sort.SearchFloat64s(f, q)
+	return t[min(i, len(t)-1)]
+}
+
+// Histogram returns nbins bin edges (nbins+1) and the (weighted) travel-time probability density within each bin
+func (b *Breakthrough) Histogram(nbins int) (edges, pdf []float64) {
+	if len(b.Arrivals) == 0 || nbins <= 0 {
+		return nil, nil
+	}
+	w := (b.Max - b.Min) / float64(nbins)
+	if w <= 0. {
+		w = 1.
+	}
+	edges, pdf = make([]float64, nbins+1), make([]float64, nbins)
+	for i := range edges {
+		edges[i] = b.Min + float64(i)*w
+	}
+	for _, a := range b.Arrivals {
+		i := min(int((a.T-b.Min)/w), nbins-1)
+		pdf[i] += a.W
+	}
+	for i := range pdf {
+		pdf[i] /= b.W * w
+	}
+	return
+}
+
+// SaveCSV saves the breakthrough curve: particle arrivals with their cumulative probability
+func (b *Breakthrough) SaveCSV(fp string) {
+	csvw := mmio.NewCSVwriter(fp)
+	csvw.WriteHead("pid,time,weight,cdf")
+	_, f := b.CDF()
+	for i, a := range b.Arrivals {
+		csvw.WriteLine(a.PID, a.T, a.W, f[i])
+	}
+	csvw.Close()
+}
+
+// SaveHistogramCSV saves the travel-time histogram of nbins bins
+func (b *Breakthrough) SaveHistogramCSV(fp string, nbins int) {
+	csvw := mmio.NewCSVwriter(fp)
+	csvw.WriteHead("from,to,pdf")
+	e, pdf := b.Histogram(nbins)
+	for i, v := range pdf {
+		csvw.WriteLine(e[i], e[i+1], v)
+	}
+	csvw.Close()
+}
+
+// SaveBreakthroughsCSV saves the travel-time moments of every receptor
+func SaveBreakthroughsCSV(fp string, bs []*Breakthrough) {
+	csvw := mmio.NewCSVwriter(fp)
+	csvw.WriteHead("receptor,n,weight,mean,var,skew,min,max,p05,p50,p95")
+	for _, b := range bs {
+		csvw.WriteLine(b.Receptor, len(b.Arrivals), b.W, b.Mean, b.Var, b.Skew, b.Min, b.Max, b.Percentile(.05), b.Percentile(.5), b.Percentile(.95))
+	}
+	csvw.Close()
+}
@@ -0,0 +1,45 @@
+package analysis
+
+import (
+	"math"
+	"slices"
+	"testing"
+
+	"github.com/maseology/ptrack"
+)
+
+func TestBreakthroughHistogram(t *testing.T) {
+	path := func(t0, ta float64, c, zone int) []ptrack.Particle { // released at t0 in prism 0, reaching prism c at t0+ta
+		return []ptrack.Particle{{C: 0, T: t0}, {C: c, Zone: zone, T: t0 + ta}, {C: c, Zone: zone, T: t0 + ta + 10.}}
+	}
+	pl := [][]ptrack.Particle{path(5., 1., 3, 0), path(0., 2., 3, 0), path(0., 3., 4, 7), path(2., 4., 4, 7), path(0., 1., 9, 0), path(0., 9., 3, 0)}
+	w := []float64{1., 1., 1., 1., 1., 0.} // the last pathline is not counted
+	bs, err := Breakthroughs(pl, []Receptor{PrismReceptor("well", 3), ZoneReceptor("river", 7)}, w)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bs[0].Arrivals) != 2 || len(bs[1].Arrivals) != 2 {
+		t.Fatalf("arrivals %v and %v, want 2 at each receptor", bs[0].Arrivals, bs[1].Arrivals)
+	}
+
+	all := Breakthrough{Arrivals: append(slices.Clone(bs[0].Arrivals), bs[1].Arrivals...)}
+	all.summarize()
+	if all.Min != 1. || all.Max != 4. || all.Mean != 2.5 || all.Percentile(.5) != 2. {
+		t.Errorf("min %g, max %g, mean %g and median %g, want 1, 4, 2.5 and 2", all.Min, all.Max, all.Mean, all.Percentile(.5))
+	}
+	e, pdf := all.Histogram(3)
+	if !slices.Equal(e, []float64{1., 2., 3., 4.}) || !slices.Equal(pdf, []float64{.25, .25, .5}) {
+		t.Errorf("histogram edges %v and density %v, want unit bins from 1 to 4, the maximum falling in the last bin", e, pdf)
+	}
+	s := 0.
+	for i, v := range pdf {
+		s += v * (e[i+1] - e[i])
+	}
+	if math.Abs(s-1.) > 1e-12 {
+		t.Errorf("histogram integrates to %g, want 1", s)
+	}
+
+	if _, err := Breakthroughs(pl, nil, w[:2]); err == nil {
+		t.Error("expected an error for mismatched weights")
+	}
+}
@@ -0,0 +1,305 @@
+// Package analysis provides post-processing of particle tracking results.
+package analysis
+
+import (
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"sort"
+
+	"github.com/maseology/ptrack"
+)
+
+// Endpoint is the final vertex of a pathline
+type Endpoint struct {
+	PID        int     // pathline index
+	Start, End int     // starting and ending prism IDs
+	X, Y, Z    float64 // endpoint coordinates
+	T          float64 // travel time, from the first to the last vertex
+}
+
+// Endpoints returns the endpoints of pathlines pl, as returned from tracking or loaded using ptrack.LoadPathlinesGOB
+func Endpoints(pl [][]ptrack.Particle) []Endpoint {
+	e := make([]Endpoint, 0, len(pl))
+	for k, a := range pl {
+		if len(a) == 0 {
+			continue
+		}
+		p0, p1 := a[0], a[len(a)-1]
+		e = append(e, Endpoint{PID: k, Start: p0.C, End: p1.C, X: p1.X, Y: p1.Y, Z: p1.Z, T: p1.T - p0.T})
+	}
+	return e
+}
+
+// Cluster is a group of endpoints
+type Cluster struct {
+	ID         int
+	X, Y, Z, T float64     // centroid, and mean travel time
+	Members    []int       // endpoint indices
+	Sources    map[int]int // starting prism IDs feeding the cluster, with their number of pathlines
+}
+
+// Clusters holds the endpoint cluster membership. Label is -1 for endpoints not belonging to any cluster (DBSCAN noise).
+type Clusters struct {
+	Endpoints []Endpoint
+	Label     []int
+	Clusters  []Cluster
+}
+
+// tscale is the weight given to travel time in the distance metric: one unit of time is equivalent to tscale units of distance;
+// set to zero to cluster in 3D space only
+func coords(e Endpoint, tscale float64) [4]float64 {
+	return [4]float64{e.X, e.Y, e.Z, e.T * tscale}
+}
+
+func dist2(a, b [4]float64) float64 {
+	s := 0.
+	for k := range 4 {
+		s += (a[k] - b[k]) * (a[k] - b[k])
+	}
+	return s
+}
+
+// DBSCAN clusters endpoints e by density: endpoints having at least minPts neighbours within distance eps seed a cluster.
+// Travel time is included in the metric when tscale > 0. An error is returned when eps is not positive.
+// Ester, M., H.-P. Kriegel, J. Sander, X. Xu, 1996. A density-based algorithm for discovering clusters in large spatial databases with noise. Proceedings of the Second International Conference on Knowledge Discovery and Data Mining (KDD-96): 226-231.
+func DBSCAN(e []Endpoint, eps float64, minPts int, tscale float64) (*Clusters, error) {
+	if eps <= 0. || math.IsNaN(eps) {
+		return nil, fmt.Errorf("DBSCAN: eps must be positive, got %v", eps)
+	}
+	x := make([][4]float64, len(e))
+	for i := range e {
+		x[i] = coords(e[i], tscale)
+	}
+
+	// bin endpoints by eps, such that neighbours are found among adjacent bins
+	key := func(c [4]float64) [4]int {
+		var k [4]int
+		for j := range 4 {
+			k[j] = int(math.Floor(c[j] / eps))
+		}
+		return k
+	}
+	bins := make(map[[4]int][]int)
+	for i := range x {
+		k := key(x[i])
+		bins[k] = append(bins[k], i)
+	}
+	eps2 := eps * eps
+	neighbours := func(i int) []int {
+		var o []int
+		k := key(x[i])
+		for a := -1; a <= 1; a++ {
+			for b := -1; b <= 1; b++ {
+				for c := -1; c <= 1; c++ {
+					for d := -1; d <= 1; d++ {
+						for _, j := range bins[[4]int{k[0] + a, k[1] + b, k[2] + c, k[3] + d}] {
+							if dist2(x[i], x[j]) <= eps2 {
+								o = append(o, j)
+							}
+						}
+					}
+				}
+			}
+		}
+		return o
+	}
+
+	const unvisited, noise = -2, -1
+	lbl := make([]int, len(e))
+	for i := range lbl {
+		lbl[i] = unvisited
+	}
+	nc := 0
+	for i := range e {
+		if lbl[i] != unvisited {
+			continue
+		}
+		nb := neighbours(i)
+		if len(nb) < minPts {
+			lbl[i] = noise
+			continue
+		}
+		lbl[i] = nc
+		queue := nb
+		for len(queue) > 0 {
+			j := queue[0]
+			queue = queue[1:]
+			if lbl[j] == noise {
+				lbl[j] = nc // border point
+			}
+			if lbl[j] != unvisited {
+				continue
+			}
+			lbl[j] = nc
+			if nbj := neighbours(j); len(nbj) >= minPts {
+				queue = append(queue, nbj...) // core point
+			}
+		}
+		nc++
+	}
+	return summarize(e, lbl, nc), nil
+}
+
+// KMeans partitions endpoints e into k clusters using Lloyd's algorithm with k-means++ seeding,
+// iterating until membership no longer changes or maxIter is reached.
+// Travel time is included in the metric when tscale > 0.
+func KMeans(e []Endpoint, k int, tscale float64, seed uint64, maxIter int) *Clusters {
+	n := len(e)
+	k = min(k, n)
+	if k <= 0 {
+		return summarize(e, make([]int, n), 0)
+	}
+	x := make([][4]float64, n)
+	for i := range e {
+		x[i] = coords(e[i], tscale)
+	}
+
+	// k-means++ seeding
+	rng := rand.New(rand.NewPCG(seed, 0))
+	cs := make([][4]float64, 0, k)
+	cs = append(cs, x[rng.IntN(n)])
+	dmin := make([]float64, n)
+	for len(cs) < k {
+		s := 0.
+		for i := range x {
+			dmin[i] = math.MaxFloat64
+			for _, c := range cs {
+				dmin[i] = math.Min(dmin[i], dist2(x[i], c))
+			}
+			s += dmin[i]
+		}
+		if s == 0. {
+			break // fewer distinct endpoints than clusters
+		}
+		r, j := rng.Float64()*s, 0
+		for ; j < n-1; j++ {
+			r -= dmin[j]
+			if r <= 0. {
+				break
+			}
+		}
+		cs = append(cs, x[j])
+	}
+	k = len(cs)
+
+	lbl := make([]int, n)
+	for i := range lbl {
+		lbl[i] = -1
+	}
+	for range max(maxIter, 1) {
+		chng := false
+		for i := range x {
+			jx, dx := 0, math.MaxFloat64
+			for j, c := range cs {
+				if d := dist2(x[i], c); d < dx {
+					jx, dx = j, d
+				}
+			}
+			if lbl[i] != jx {
+				lbl[i] = jx
+				chng = true
+			}
+		}
+		if !chng {
+			break
+		}
+		updateCentroids(x, lbl, cs)
+	}
+	return summarize(e, lbl, k)
+}
+
+// updateCentroids moves centroids cs to the mean of their members lbl. A cluster left empty is re-seeded
+// at the endpoint farthest from its own centroid, which then joins it on the next assignment.
+func updateCentroids(x [][4]float64, lbl []int, cs [][4]float64) {
+	cnt := make([]int, len(cs))
+	sum := make([][4]float64, len(cs))
+	for i, l := range lbl {
+		for a := range 4 {
+			sum[l][a] += x[i][a]
+		}
+		cnt[l]++
+	}
+	var empty []int
+	for j := range cs {
+		if cnt[j] == 0 {
+			empty = append(empty, j)
+			continue
+		}
+		for a := range 4 {
+			cs[j][a] = sum[j][a] / float64(cnt[j])
+		}
+	}
+	used := make(map[int]bool, len(empty))
+	for _, j := range empty {
+		ix, dx := -1, -1.
+		for i := range x {
+			if used[i] {
+				continue
+			}
+			if d := dist2(x[i], cs[lbl[i]]); d > dx {
+				ix, dx = i, d
+			}
+		}
+		if ix < 0 {
+			break
+		}
+		used[ix] = true
+		cs[j] = x[ix]
+	}
+}
+
+// summarize builds the cluster centroids and sources from membership lbl, dropping empty clusters
+func summarize(e []Endpoint, lbl []int, nc int) *Clusters {
+	cl := make([]Cluster, nc)
+	for j := range cl {
+		cl[j].Sources = make(map[int]int)
+	}
+	for i, l := range lbl {
+		if l < 0 {
+			continue
+		}
+		c := &cl[l]
+		c.Members = append(c.Members, i)
+		c.X += e[i].X
+		c.Y += e[i].Y
+		c.Z += e[i].Z
+		c.T += e[i].T
+		c.Sources[e[i].Start]++
+	}
+
+	// renumber, such that empty clusters are removed
+	xr, o := make([]int, nc), make([]Cluster, 0, nc)
+	for j, c := range cl {
+		xr[j] = -1
+		if len(c.Members) == 0 {
+			continue
+		}
+		f := float64(len(c.Members))
+		c.X /= f
+		c.Y /= f
+		c.Z /= f
+		c.T /= f
+		c.ID = len(o)
+		xr[j] = c.ID
+		o = append(o, c)
+	}
+	l := make([]int, len(lbl))
+	for i, v := range lbl {
+		l[i] = -1
+		if v >= 0 {
+			l[i] = xr[v]
+		}
+	}
+	return &Clusters{Endpoints: e, Label: l, Clusters: o}
+}
+
+// SourcePrisms returns the (sorted) starting prism IDs feeding cluster j
+func (c *Clusters) SourcePrisms(j int) []int {
+	s := make([]int, 0, len(c.Clusters[j].Sources))
+	for i := range c.Clusters[j].Sources {
+		s = append(s, i)
+	}
+	sort.Ints(s)
+	return s
+}
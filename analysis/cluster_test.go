@@ -0,0 +1,53 @@
+package analysis
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestDBSCANRejectsNonPositiveEps(t *testing.T) {
+	e := []Endpoint{{X: 0.}, {X: 1.}}
+	for _, eps := range []float64{0., -1.} {
+		if _, err := DBSCAN(e, eps, 1, 0.); err == nil {
+			t.Errorf("eps %v: expected an error", eps)
+		}
+	}
+	c, err := DBSCAN(e, 2., 1, 0.)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(c.Clusters) != 1 {
+		t.Errorf("expected 1 cluster, got %d", len(c.Clusters))
+	}
+}
+
+func TestKMeansReseedsEmptyCluster(t *testing.T) {
+	x := [][4]float64{{0, 0, 0, 0}, {1, 0, 0, 0}, {10, 0, 0, 0}}
+	lbl := []int{0, 0, 0} // cluster 1 lost all its members
+	cs := [][4]float64{{5, 0, 0, 0}, {-100, 0, 0, 0}}
+	updateCentroids(x, lbl, cs)
+
+	if want := [4]float64{11. / 3., 0, 0, 0}; cs[0] != want {
+		t.Errorf("cluster 0 centroid: got %v, want %v", cs[0], want)
+	}
+	if want := x[2]; cs[1] != want {
+		t.Errorf("empty cluster re-seeded at %v, want the farthest endpoint %v", cs[1], want)
+	}
+}
+
+func TestSaveCSVError(t *testing.T) {
+	c, err := DBSCAN([]Endpoint{{X: 0.}, {X: 1.}}, 2., 1, 0.)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fp := filepath.Join(t.TempDir(), "missing", "c.csv")
+	if err := c.SaveCSV(fp); err == nil {
+		t.Error("SaveCSV: expected an error writing to a missing directory")
+	}
+	if err := c.SaveClustersCSV(fp); err == nil {
+		t.Error("SaveClustersCSV: expected an error writing to a missing directory")
+	}
+	if err := c.SaveCSV(filepath.Join(t.TempDir(), "c.csv")); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,79 @@
+package analysis
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/maseology/mmio"
+	geojson "github.com/paulmach/go.geojson"
+)
+
+// SaveGeojson saves the endpoints as points, attributed with their cluster, along with the cluster centroids
+func (c *Clusters) SaveGeojson(fp string) error {
+	fc := geojson.NewFeatureCollection()
+	for i, e := range c.Endpoints {
+		f := geojson.NewPointFeature([]float64{e.X, e.Y, e.Z})
+		f.SetProperty("pid", e.PID)
+		f.SetProperty("cluster", c.Label[i])
+		f.SetProperty("start", e.Start)
+		f.SetProperty("end", e.End)
+		f.SetProperty("time", e.T)
+		fc.AddFeature(f)
+	}
+	for _, cl := range c.Clusters {
+		f := geojson.NewPointFeature([]float64{cl.X, cl.Y, cl.Z})
+		f.SetProperty("cluster", cl.ID)
+		f.SetProperty("centroid", true)
+		f.SetProperty("n", len(cl.Members))
+		f.SetProperty("nsources", len(cl.Sources))
+		f.SetProperty("time", cl.T)
+		fc.AddFeature(f)
+	}
+
+	rawJSON, err := fc.MarshalJSON()
+	if err != nil {
+		return fmt.Errorf("SaveGeojson MarshalJSON error: %w", err)
+	}
+	mmio.WriteString(fp, string(rawJSON)+"\n")
+	return nil
+}
+
+// SaveCSV saves endpoint cluster membership
+func (c *Clusters) SaveCSV(fp string) error {
+	var sb strings.Builder
+	sb.WriteString("pid,cluster,start,end,x,y,z,time\n")
+	for i, e := range c.Endpoints {
+		csvLine(&sb, e.PID, c.Label[i], e.Start, e.End, e.X, e.Y, e.Z, e.T)
+	}
+	if err := os.WriteFile(fp, []byte(sb.String()), 0644); err != nil {
+		return fmt.Errorf("SaveCSV: %w", err)
+	}
+	return nil
+}
+
+// SaveClustersCSV saves the cluster centroids and the starting prisms feeding each cluster
+func (c *Clusters) SaveClustersCSV(fp string) error {
+	var sb strings.Builder
+	sb.WriteString("cluster,n,x,y,z,time,source,count\n")
+	for j, cl := range c.Clusters {
+		for _, s := range c.SourcePrisms(j) {
+			csvLine(&sb, cl.ID, len(cl.Members), cl.X, cl.Y, cl.Z, cl.T, s, cl.Sources[s])
+		}
+	}
+	if err := os.WriteFile(fp, []byte(sb.String()), 0644); err != nil {
+		return fmt.Errorf("SaveClustersCSV: %w", err)
+	}
+	return nil
+}
+
+// csvLine appends the comma-delimited values v to sb
+func csvLine(sb *strings.Builder, v ...any) {
+	for i, a := range v {
+		if i > 0 {
+			sb.WriteByte(',')
+		}
+		fmt.Fprint(sb, a)
+	}
+	sb.WriteByte('\n')
+}
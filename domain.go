@@ -11,16 +11,28 @@ import (
 
 // Domain is a set of cells that constitute a model
 type Domain struct {
-	VF       map[int]VelocityFielder // prism velocity field
-	pt       ParticleTracker         // needed only for waterloo method
-	prsms    map[int]*Prism          // prism dimensions
-	flx      map[int][]float64       // prism flux
-	conn     map[int][]int           // prism connectivity
-	zw       map[int]complex128      // well(/point) coordinates
-	qw       map[int]float64         // well(/point) flux
-	Nly      int                     // (optional) number of layers
-	Minthick float64                 // "pinchout" thickness
-	isrev    bool                    // vector field has been reverse
+	VF       map[int]VelocityFielder         // prism velocity field
+	pt       ParticleTracker                 // needed only for waterloo method
+	prsms    map[int]*Prism                  // prism dimensions
+	flx      map[int][]float64               // prism flux
+	conn     map[int][]int                   // prism connectivity
+	shrd     map[int]map[int][]faceNeighbour // (optional) faces shared with several prisms, by prism and face
+	zw       map[int][]Well                  // point sources/sinks (wells, at their coordinates)
+	qw       map[int]float64                 // total point flux (wells and boundaries)
+	Nly      int                             // (optional) number of layers, set before New to size the spatial index
+	Minthick float64                         // "pinchout" thickness
+	isrev    bool                            // vector field has been reverse
+	steps    []*TimeStep                     // (optional) transient flow field, in time order
+	zone     map[int]int                     // (optional) prism zones
+	stopzn   map[int]bool                    // (optional) terminating zones
+	wsopt    WeakSinkOption                  // weak sink policy
+	wsfrac   float64                         // weak sink policy threshold (WeakSinkFraction)
+	idx      *binIndex                       // spatial index for point-in-prism lookups
+	rw       *RandomWalk                     // (optional) random-walk tracking scheme
+	sol      *Solute                         // (optional) solute retardation and decay
+	wmopt    WaterlooOptions                 // Waterloo method construction
+	xf       Transform                       // (optional) model to world coordinates
+	crs      Coordinates                     // coordinate system of particle input, Locate and exports
 }
 
 // Nprism returns the prisms (cells) in the domain
@@ -35,15 +47,11 @@ func (d *Domain) New(prsms map[int]*Prism, conn map[int][]int, pflxs map[int][]f
 	d.prsms = prsms
 	d.conn = conn
 	d.flx = pflxs
+	d.shrd = nil
 	d.isrev = false
-	d.zw = make(map[int]complex128, len(qwell)) //, len(d.prsms))
-	d.qw = make(map[int]float64, len(qwell))
-	for i, q := range d.prsms {
-		if qwell[i] != 0. {
-			d.zw[i] = complex(q.CentroidXY())
-			d.qw[i] = qwell[i]
-		}
-	}
+	d.steps = nil
+	d.zw, d.qw = wellsAt(prsms, qwell, nil)
+	d.buildIndex()
 	// for i, q := range d.prsms {
 	// 	zwt := complex(q.CentroidXY())
 	// 	if qwell[i] == 0. {
@@ -57,59 +65,93 @@ func (d *Domain) New(prsms map[int]*Prism, conn map[int][]int, pflxs map[int][]f
 
 func (d *Domain) ReverseVectorField() {
 	d.isrev = !d.isrev
+	if len(d.steps) > 0 {
+		te := d.steps[len(d.steps)-1].T1
+		for _, s := range d.steps {
+			s.reverse(te)
+		}
+		return
+	}
 	for k, vf := range d.VF {
 		vf.ReverseVectorField()
 		d.VF[k] = vf
 	}
 }
 
-// MakeWaterloo creates velocity field using the Waterloo Method
-func (d *Domain) MakeWaterloo(pt ParticleTracker) {
+// MakeWaterloo creates velocity field using the Waterloo Method. Prism solutions are built concurrently
+// (see WaterlooOptions.Workers); prisms that fail are all reported.
+func (d *Domain) MakeWaterloo(pt ParticleTracker) error {
 	fmt.Println(" building Waterloo method flow field..")
-	d.VF = make(map[int]VelocityFielder, len(d.prsms))
-	for i, q := range d.prsms {
-		var wm WatMethSoln
-		nf := len(d.flx[i])
-		ql, qb, qt := d.flx[i][:nf-2], d.flx[i][nf-2], d.flx[i][nf-1] // [laterals CCW order]-bottom-top; left-up-right-down-bottom-top
-		wm.New(i, q, ql, d.zw[i], -qt, qb, d.qw[i], 80, 30, false)
-		d.VF[i] = &wm
+	if err := d.buildVF(func(prsms map[int]*Prism, flx map[int][]float64, zw map[int][]Well, _ float64) (map[int]VelocityFielder, error) {
+		return d.buildWaterloo(prsms, flx, zw)
+	}); err != nil {
+		return fmt.Errorf("MakeWaterloo: %w", err)
 	}
 	d.pt = pt
+	return nil
 }
 
 // MakePollock creates velocity field using the Pollock (MODPATH) Method
-// much be a quadralinear domain
-func (d *Domain) MakePollock(dt float64) {
+// much be a quadralinear domain. In transient cases, prisms are built to the water table at the middle of each time step.
+func (d *Domain) MakePollock(dt float64) error {
 	fmt.Println(" building Pollock method flow field..")
-	d.VF = make(map[int]VelocityFielder, len(d.prsms))
-	for i, q := range d.prsms {
-		var pm PollockMethod
-		ql, qb, qt := d.flx[i][:4], d.flx[i][4], d.flx[i][5] // left-up-right-down-bottom-top
-		// pm.New(q, d.zw[i], ql[0], -ql[2], ql[3], -ql[1], qb, -qt, dt) // q (prism), well (assumed centroid), Qx0, Qx1, Qy0, Qy1, Qz0, Qz1,  dt
-		pm.New(q, d.zw[i], ql[0], -ql[2], ql[3], -ql[1], qb, -qt, dt) // q (prism), well (assumed centroid), Qx0, Qx1, Qy0, Qy1, Qz0, Qz1,  dt
-		d.VF[i] = &pm
+	if err := d.buildVF(func(prsms map[int]*Prism, flx map[int][]float64, zw map[int][]Well, ts float64) (map[int]VelocityFielder, error) {
+		vf := make(map[int]VelocityFielder, len(prsms))
+		for i, q := range prsms {
+			var pm PollockMethod
+			if len(flx[i]) != 6 {
+				return nil, fmt.Errorf("prism %d has %d fluxes, expecting left-up-right-down-bottom-top", i, len(flx[i]))
+			}
+			if q.Dbdt != 0. {
+				c := *q
+				c.Bn += q.Dbdt * ts / 2. // time-averaged water table, specific yield storage entering across its top
+				q = &c
+			}
+			ql, qb, qt := flx[i][:4], flx[i][4], flx[i][5] // left-up-right-down-bottom-top
+			zwl := cmplx.NaN()
+			if len(zw[i]) > 0 {
+				zwl = complex(q.CentroidXY()) // sinks are distributed over the prism
+			}
+			// pm.New(q, zw[i], ql[0], -ql[2], ql[3], -ql[1], qb, -qt, dt) // q (prism), well (assumed centroid), Qx0, Qx1, Qy0, Qy1, Qz0, Qz1,  dt
+			if err := pm.New(q, zwl, ql[0], -ql[2], ql[3], -ql[1], qb, -qt, dt); err != nil { // q (prism), well (assumed centroid), Qx0, Qx1, Qy0, Qy1, Qz0, Qz1,  dt
+				return nil, fmt.Errorf("prism %d: %w", i, err)
+			}
+			vf[i] = &pm
+		}
+		return vf, nil
+	}); err != nil {
+		return fmt.Errorf("MakePollock: %w", err)
 	}
 	// d.PT = &PollockMethod{}
+	return nil
 }
 
 // MakeVector creates velocity field based on a uniform prism velocity vector
-func (d *Domain) MakeVector() {
+func (d *Domain) MakeVector() error {
 	fmt.Println(" Building vector-based flow field..")
-	d.VF = make(map[int]VelocityFielder, len(d.prsms))
-	for i, q := range d.prsms {
-		var vm VectorMethSoln
-		r, zc := 0., 0+0.i
-		for _, c := range q.Z {
-			zc += c
-		}
-		zc /= complex(float64(len(q.Z)), 0)
-		for j := 0; j < len(q.Z); j++ {
-			r = math.Max(r, cmplx.Abs(q.Z[j]-zc))
+	if err := d.buildVF(func(prsms map[int]*Prism, flx map[int][]float64, _ map[int][]Well, _ float64) (map[int]VelocityFielder, error) {
+		vf := make(map[int]VelocityFielder, len(prsms))
+		for i, q := range prsms {
+			var vm VectorMethSoln
+			r, zc := 0., 0+0.i
+			for _, c := range q.Z {
+				zc += c
+			}
+			zc /= complex(float64(len(q.Z)), 0)
+			for j := 0; j < len(q.Z); j++ {
+				r = math.Max(r, cmplx.Abs(q.Z[j]-zc))
+			}
+			if err := vm.New(zc, flx[i], q.Por, r); err != nil {
+				return nil, fmt.Errorf("prism %d: %w", i, err)
+			}
+			vf[i] = &vm
 		}
-		vm.New(zc, d.flx[i], q.Por, r)
-		d.VF[i] = &vm
+		return vf, nil
+	}); err != nil {
+		return fmt.Errorf("MakeVector: %w", err)
 	}
 	// d.PT = &VectorMethSoln{}
+	return nil
 }
 
 // Print properties of the domain
@@ -148,17 +190,13 @@ func (d *Domain) getExtent() (zn, zx, yn, yx, xn, xx float64) {
 // position index: left-up-right-down-bottom-top
 func (d *Domain) ParticleToPrismIDs(p *Particle, pidFrom int) []int {
 	var pids []int
-	if pidFrom < 0 { // brute force solution
-		for i, r := range d.prsms {
-			if r.Contains(p) {
-				pids = append(pids, i)
-			}
-		}
+	if pidFrom < 0 {
+		return d.locateAll(p.X, p.Y, p.Z)
 	} else {
 		if _, ok := d.conn[pidFrom]; !ok {
 			fmt.Printf("ParticleToPrismIDs bad prism ID   %d\n", pidFrom)
 		}
-		for _, pid := range d.conn[pidFrom] {
+		for _, pid := range d.neighbours(pidFrom) {
 			if pid < 0 { // left-up-right-down-bottom-top
 				continue
 			}
@@ -180,12 +218,8 @@ func (d *Domain) ParticleToPrismIDs(p *Particle, pidFrom int) []int {
 	}
 
 	if len(pids) == 0 {
-		// brute force solution
-		for i, r := range d.prsms {
-			if r.Contains(p) {
-				pids = append(pids, i)
-				break
-			}
+		if pids = d.locateAll(p.X, p.Y, p.Z); len(pids) > 1 {
+			pids = pids[:1]
 		}
 	}
 	return pids
@@ -0,0 +1,323 @@
+package ptrack
+
+import (
+	"encoding/gob"
+	"fmt"
+	"os"
+
+	"github.com/maseology/mmio"
+)
+
+const (
+	domainGobMagic   = "ptrack.Domain"
+	domainGobVersion = 6 // increment when gobDomain changes
+)
+
+type gobHeader struct {
+	Magic   string
+	Version int
+}
+
+// gobDomain is the saved state of a Domain
+type gobDomain struct {
+	Prsms    map[int]*Prism
+	Flx      map[int][]float64
+	Conn     map[int][]int
+	Shrd     map[int]map[int][]gobNeighbour
+	Zw       map[int][]Well
+	Qw       map[int]float64
+	VF       map[int]gobVF
+	Tracker  gobTracker
+	Steps    []gobStep
+	Nly      int
+	Minthick float64
+	Isrev    bool
+	Wmopt    gobWaterlooOptions
+	Xf       Transform
+	Crs      Coordinates
+}
+
+type gobStep struct {
+	KPER, KSTP int
+	T0, T1     float64
+	VF         map[int]gobVF
+	Prsms      map[int]*Prism
+	Flx        map[int][]float64
+	Shrd       map[int]map[int][]gobNeighbour
+	Zw         map[int][]Well
+	Qw         map[int]float64
+	Sto        bool
+}
+
+type gobNeighbour struct {
+	PID int
+	Q   float64
+}
+
+// gobVF holds one of the velocity field solutions
+type gobVF struct {
+	W *gobWaterloo
+	P *gobPollock
+	V *gobVector
+}
+
+type gobWaterloo struct {
+	AT, Zwl    []complex128
+	Qwl        []float64
+	Zc, R      complex128
+	Qv, Ql, Qb float64
+	Err        float64
+	Unmet      bool
+	M, N, Nf   int
+}
+
+type gobPollock struct {
+	Zc, Zwl                      complex128
+	X0, Y0, Z0, X1, Y1, Z1       float64
+	Vx0, Vx1, Vy0, Vy1, Vz0, Vz1 float64
+	R, Ax, Ay, Az                float64
+	Dt                           float64
+}
+
+type gobVector struct {
+	Zc                  complex128
+	R, Vx, Vy, Vzt, Vzb float64
+}
+
+type gobTracker struct {
+	Kind   string // empty when no tracker is set
+	Ds, Dt float64
+}
+
+type gobWaterlooOptions struct {
+	M, N, NMax, Workers int
+	Tol                 float64
+}
+
+// SaveGob saves the domain, including its built velocity fields, to a (versioned) gob file such that a flow field
+// need only be built once for many tracking runs. Tracking options (zones, weak sink policy, random walk, solute)
+// and the Waterloo progress function are not saved.
+func (d *Domain) SaveGob(fp string) error {
+	g := gobDomain{
+		Prsms:    d.prsms,
+		Flx:      d.flx,
+		Conn:     d.conn,
+		Shrd:     toGobShared(d.shrd),
+		Zw:       d.zw,
+		Qw:       d.qw,
+		Nly:      d.Nly,
+		Minthick: d.Minthick,
+		Isrev:    d.isrev,
+		Wmopt:    gobWaterlooOptions{M: d.wmopt.M, N: d.wmopt.N, NMax: d.wmopt.NMax, Workers: d.wmopt.Workers, Tol: d.wmopt.Tol},
+		Xf:       d.xf,
+		Crs:      d.crs,
+	}
+	var err error
+	if len(d.steps) == 0 { // otherwise the domain's field is that of the first time step, saved with the steps
+		if g.VF, err = toGobVF(d.VF); err != nil {
+			return fmt.Errorf("SaveGob: %w", err)
+		}
+	}
+	if g.Tracker, err = toGobTracker(d.pt); err != nil {
+		return fmt.Errorf("SaveGob: %w", err)
+	}
+	for k, s := range d.steps {
+		gs := gobStep{KPER: s.KPER, KSTP: s.KSTP, T0: s.T0, T1: s.T1, Prsms: s.prsms, Flx: s.flx, Shrd: toGobShared(s.shrd), Zw: s.zw, Qw: s.qw, Sto: s.sto}
+		if gs.VF, err = toGobVF(s.VF); err != nil {
+			return fmt.Errorf("SaveGob time step %d: %w", k, err)
+		}
+		g.Steps = append(g.Steps, gs)
+	}
+
+	f, err := os.Create(fp)
+	if err != nil {
+		return fmt.Errorf("SaveGob: %w", err)
+	}
+	defer f.Close()
+	enc := gob.NewEncoder(f)
+	if err := enc.Encode(gobHeader{Magic: domainGobMagic, Version: domainGobVersion}); err != nil {
+		return fmt.Errorf("SaveGob: %w", err)
+	}
+	if err := enc.Encode(g); err != nil {
+		return fmt.Errorf("SaveGob: %w", err)
+	}
+	return f.Close()
+}
+
+// LoadDomainGob loads a domain saved using SaveGob, ready for tracking
+func LoadDomainGob(fp string) (*Domain, error) {
+	if _, ok := mmio.FileExists(fp); !ok {
+		return nil, fmt.Errorf("LoadDomainGob: file %s cannot be found", fp)
+	}
+	f, err := os.Open(fp)
+	if err != nil {
+		return nil, fmt.Errorf("LoadDomainGob: %w", err)
+	}
+	defer f.Close()
+	dec := gob.NewDecoder(f)
+	var h gobHeader
+	if err := dec.Decode(&h); err != nil || h.Magic != domainGobMagic {
+		return nil, fmt.Errorf("LoadDomainGob: %s is not a saved domain", fp)
+	}
+	if h.Version != domainGobVersion {
+		return nil, fmt.Errorf("LoadDomainGob: %s saved as version %d, version %d expected", fp, h.Version, domainGobVersion)
+	}
+	var g gobDomain
+	if err := dec.Decode(&g); err != nil {
+		return nil, fmt.Errorf("LoadDomainGob: %w", err)
+	}
+
+	d := &Domain{
+		prsms:    g.Prsms,
+		flx:      g.Flx,
+		conn:     g.Conn,
+		shrd:     fromGobShared(g.Shrd),
+		zw:       g.Zw,
+		qw:       g.Qw,
+		Nly:      g.Nly,
+		Minthick: g.Minthick,
+		isrev:    g.Isrev,
+		wmopt:    WaterlooOptions{M: g.Wmopt.M, N: g.Wmopt.N, NMax: g.Wmopt.NMax, Workers: g.Wmopt.Workers, Tol: g.Wmopt.Tol},
+		xf:       g.Xf,
+		crs:      g.Crs,
+	}
+	if d.VF, err = fromGobVF(g.VF); err != nil {
+		return nil, fmt.Errorf("LoadDomainGob: %w", err)
+	}
+	if d.pt, err = fromGobTracker(g.Tracker); err != nil {
+		return nil, fmt.Errorf("LoadDomainGob: %w", err)
+	}
+	for k, gs := range g.Steps {
+		s := &TimeStep{KPER: gs.KPER, KSTP: gs.KSTP, T0: gs.T0, T1: gs.T1, prsms: gs.Prsms, flx: gs.Flx, shrd: fromGobShared(gs.Shrd), zw: gs.Zw, qw: gs.Qw, sto: gs.Sto}
+		if s.VF, err = fromGobVF(gs.VF); err != nil {
+			return nil, fmt.Errorf("LoadDomainGob time step %d: %w", k, err)
+		}
+		d.steps = append(d.steps, s)
+	}
+	if len(d.steps) > 0 {
+		d.VF = d.steps[0].VF // shared, as built, such that reversing the steps reverses the domain's field
+	}
+	d.buildIndex()
+	return d, nil
+}
+
+func toGobVF(vf map[int]VelocityFielder) (map[int]gobVF, error) {
+	if vf == nil {
+		return nil, nil
+	}
+	g := make(map[int]gobVF, len(vf))
+	for i, v := range vf {
+		switch t := v.(type) {
+		case *WatMethSoln:
+			g[i] = gobVF{W: &gobWaterloo{AT: t.aT, Zwl: t.zwl, Qwl: t.qwl, Zc: t.zc, R: t.r, Qv: t.qv, Ql: t.ql, Qb: t.qb, Err: t.err, Unmet: t.unmet, M: t.m, N: t.n, Nf: t.nf}}
+		case *PollockMethod:
+			g[i] = gobVF{P: &gobPollock{
+				Zc: t.zc, Zwl: t.zwl,
+				X0: t.x0, Y0: t.y0, Z0: t.z0, X1: t.x1, Y1: t.y1, Z1: t.z1,
+				Vx0: t.vx0, Vx1: t.vx1, Vy0: t.vy0, Vy1: t.vy1, Vz0: t.vz0, Vz1: t.vz1,
+				R: t.r, Ax: t.ax, Ay: t.ay, Az: t.az,
+				Dt: t.dt,
+			}}
+		case *VectorMethSoln:
+			g[i] = gobVF{V: &gobVector{Zc: t.zc, R: t.r, Vx: t.vx, Vy: t.vy, Vzt: t.vzt, Vzb: t.vzb}}
+		default:
+			return nil, fmt.Errorf("prism %d: velocity field type %T cannot be saved", i, v)
+		}
+	}
+	return g, nil
+}
+
+func fromGobVF(g map[int]gobVF) (map[int]VelocityFielder, error) {
+	if g == nil {
+		return nil, nil
+	}
+	vf := make(map[int]VelocityFielder, len(g))
+	for i, v := range g {
+		switch {
+		case v.W != nil:
+			t := v.W
+			vf[i] = &WatMethSoln{aT: t.AT, zwl: t.Zwl, qwl: t.Qwl, zc: t.Zc, r: t.R, qv: t.Qv, ql: t.Ql, qb: t.Qb, err: t.Err, unmet: t.Unmet, m: t.M, n: t.N, nf: t.Nf}
+		case v.P != nil:
+			t := v.P
+			vf[i] = &PollockMethod{
+				zc: t.Zc, zwl: t.Zwl,
+				x0: t.X0, y0: t.Y0, z0: t.Z0, x1: t.X1, y1: t.Y1, z1: t.Z1,
+				vx0: t.Vx0, vx1: t.Vx1, vy0: t.Vy0, vy1: t.Vy1, vz0: t.Vz0, vz1: t.Vz1,
+				r: t.R, ax: t.Ax, ay: t.Ay, az: t.Az,
+				dt: t.Dt,
+			}
+		case v.V != nil:
+			t := v.V
+			vf[i] = &VectorMethSoln{zc: t.Zc, r: t.R, vx: t.Vx, vy: t.Vy, vzt: t.Vzt, vzb: t.Vzb}
+		default:
+			return nil, fmt.Errorf("prism %d: no velocity field saved", i)
+		}
+	}
+	return vf, nil
+}
+
+func toGobTracker(pt ParticleTracker) (gobTracker, error) {
+	switch t := pt.(type) {
+	case nil:
+		return gobTracker{}, nil
+	case *EulerSpace:
+		return gobTracker{Kind: "EulerSpace", Ds: t.Ds}, nil
+	case *EulerTime:
+		return gobTracker{Kind: "EulerTime", Dt: t.Dt}, nil
+	case *RungeKutta:
+		return gobTracker{Kind: "RungeKutta", Dt: t.Dt}, nil
+	case *RungeKuttaAdaptive:
+		return gobTracker{Kind: "RungeKuttaAdaptive", Ds: t.Ds, Dt: t.Dt}, nil
+	default:
+		return gobTracker{}, fmt.Errorf("particle tracker type %T cannot be saved", pt)
+	}
+}
+
+func fromGobTracker(g gobTracker) (ParticleTracker, error) {
+	switch g.Kind {
+	case "":
+		return nil, nil
+	case "EulerSpace":
+		return &EulerSpace{Ds: g.Ds}, nil
+	case "EulerTime":
+		return &EulerTime{Dt: g.Dt}, nil
+	case "RungeKutta":
+		return &RungeKutta{Dt: g.Dt}, nil
+	case "RungeKuttaAdaptive":
+		return &RungeKuttaAdaptive{Ds: g.Ds, Dt: g.Dt}, nil
+	default:
+		return nil, fmt.Errorf("unknown particle tracker %q", g.Kind)
+	}
+}
+
+func toGobShared(shrd map[int]map[int][]faceNeighbour) map[int]map[int][]gobNeighbour {
+	if shrd == nil {
+		return nil
+	}
+	g := make(map[int]map[int][]gobNeighbour, len(shrd))
+	for i, s := range shrd {
+		g[i] = make(map[int][]gobNeighbour, len(s))
+		for j, ns := range s {
+			for _, n := range ns {
+				g[i][j] = append(g[i][j], gobNeighbour{PID: n.pid, Q: n.q})
+			}
+		}
+	}
+	return g
+}
+
+func fromGobShared(g map[int]map[int][]gobNeighbour) map[int]map[int][]faceNeighbour {
+	if g == nil {
+		return nil
+	}
+	shrd := make(map[int]map[int][]faceNeighbour, len(g))
+	for i, s := range g {
+		shrd[i] = make(map[int][]faceNeighbour, len(s))
+		for j, ns := range s {
+			for _, n := range ns {
+				shrd[i][j] = append(shrd[i][j], faceNeighbour{pid: n.PID, q: n.Q})
+			}
+		}
+	}
+	return shrd
+}
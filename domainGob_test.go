@@ -0,0 +1,77 @@
+package ptrack
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestDomainGobRoundTrip(t *testing.T) {
+	track := func(d *Domain, x float64) (pathline, Termination) {
+		t.Helper()
+		pl, _, term, err := d.TrackParticles(Particles{{X: x, Y: .5, Z: .5}}, TrackOptions{}, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return pl[0], term[0]
+	}
+	roundTrip := func(d *Domain) *Domain {
+		t.Helper()
+		fp := filepath.Join(t.TempDir(), "d.gob")
+		if err := d.SaveGob(fp); err != nil {
+			t.Fatal(err)
+		}
+		dl, err := LoadDomainGob(fp)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return dl
+	}
+
+	d := rowDomain([]float64{1., 1., 1.5, 1.5, 1.5, 1.5}, map[int]float64{1: .5})
+	if err := d.MakeVector(); err != nil {
+		t.Fatal(err)
+	}
+	dl := roundTrip(d)
+	pl, term := track(d, .5)
+	pll, terml := track(dl, .5)
+	if len(pl) < 2 || !slices.Equal(pll, pl) || terml != term {
+		t.Errorf("loaded steady-state domain tracks %d vertices (%s), want %d (%s)", len(pll), terml, len(pl), term)
+	}
+
+	// transient: the reversed field must be that of every time step, including the first
+	d = rowDomain([]float64{1., 1., 1., 1., 1., 1.}, nil)
+	fast := make(map[int][]float64, len(d.flx))
+	for i, f := range d.flx {
+		fast[i] = make([]float64, len(f))
+		for j, q := range f {
+			fast[i][j] = 2. * q
+		}
+	}
+	if err := d.AddTimeStep(1, 1, .3, d.flx, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.AddTimeStep(2, 1, 10., fast, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.MakeVector(); err != nil {
+		t.Fatal(err)
+	}
+	dl = roundTrip(d)
+	d.ReverseVectorField()
+	dl.ReverseVectorField()
+	pl, term = track(d, 4.5)
+	pll, terml = track(dl, 4.5)
+	if len(pl) < 2 || !slices.Equal(pll, pl) || terml != term {
+		t.Errorf("loaded transient domain tracks %d vertices (%s) reversed, want %d (%s)", len(pll), terml, len(pl), term)
+	}
+	for i, q := range d.prsms {
+		x, y := q.CentroidXY()
+		p := Particle{X: x, Y: y, Z: (q.Top + q.Bot) / 2.}
+		vx, _, _ := d.VF[i].PointVelocity(&p, q, 0.)
+		vxl, _, _ := dl.VF[i].PointVelocity(&p, q, 0.)
+		if vxl != vx || vx >= 0. {
+			t.Errorf("prism %d: loaded domain field has centroid velocity %g, want the reversed first time step's %g", i, vxl, vx)
+		}
+	}
+}
@@ -2,23 +2,13 @@ package ptrack
 
 import (
 	"math"
-	"math/cmplx"
 )
 
 // testTrack track particle to the next space step
 func (es *EulerSpace) TestTracktoExit(p *Particle, q *Prism, w VelocityFielder) []Particle {
 	var aout []Particle
 	nearwell := func() bool {
-		wm := w.(*WatMethSoln)
-		if !cmplx.IsNaN(wm.zwl[0]) {
-			for _, zwell := range wm.zwl {
-				zl := (complex(p.X, p.Y) - wm.zc) / wm.r // complex local coordinate
-				if cmplx.Abs(zl-zwell) < wellTol/real(wm.r) {
-					return true
-				}
-			}
-		}
-		return false
+		return w.(*WatMethSoln).nearWell(p) >= 0
 	}
 	for {
 		if !q.Contains(p) || nearwell() {
@@ -39,16 +29,7 @@ func (es *EulerSpace) TestTracktoExit(p *Particle, q *Prism, w VelocityFielder)
 func (et *EulerTime) TestTracktoExit(p *Particle, q *Prism, w VelocityFielder) []Particle {
 	var aout []Particle
 	nearwell := func() bool {
-		wm := w.(*WatMethSoln)
-		if !cmplx.IsNaN(wm.zwl[0]) {
-			for _, zwell := range wm.zwl {
-				zl := (complex(p.X, p.Y) - wm.zc) / wm.r // complex local coordinate
-				if cmplx.Abs(zl-zwell) < wellTol/real(wm.r) {
-					return true
-				}
-			}
-		}
-		return false
+		return w.(*WatMethSoln).nearWell(p) >= 0
 	}
 	for {
 		if !q.Contains(p) || nearwell() {
@@ -0,0 +1,126 @@
+package ptrack
+
+import (
+	"fmt"
+	"math"
+	"math/cmplx"
+	"slices"
+)
+
+// faceNeighbour is one of several prisms sharing a face, with the flux across its portion of the face
+type faceNeighbour struct {
+	pid int
+	q   float64 // positive in
+}
+
+// clockwise returns the (closed) polygon z in clockwise order, reporting whether it was reversed
+func clockwise(z []complex128) ([]complex128, bool) {
+	a := 0.
+	for j := range z {
+		jj := (j + 1) % len(z)
+		a += real(z[j])*imag(z[jj]) - real(z[jj])*imag(z[j])
+	}
+	if a > 0. { // counter-clockwise
+		r := slices.Clone(z)
+		slices.Reverse(r)
+		return r, true
+	}
+	return z, false
+}
+
+// faceOf returns the face of prism p shared with prism q, ordered [laterals]-bottom-top: lateral face j being the
+// polygon edge from vertex j to vertex j+1. Prisms stacked vertically share the bottom or top; otherwise the lateral face
+// is the edge overlapped most by an edge of q, such that several prisms may share a face (e.g., quadtree refinement).
+// Returns -1 when the prisms do not share a face.
+func faceOf(p, q *Prism) int {
+	nf := len(p.Z)
+	dz := mingtzero * math.Max(1., p.Top-p.Bot)
+	if q.Top <= p.Bot+dz {
+		return nf // bottom
+	}
+	if q.Bot >= p.Top-dz {
+		return nf + 1 // top
+	}
+	jx, ox := -1, 0.
+	for j := range nf {
+		a0, a1 := p.Z[j], p.Z[(j+1)%nf]
+		l := cmplx.Abs(a1 - a0)
+		u, etol := (a1-a0)/complex(l, 0.), 1e-6*l // collinearity and overlap tolerance, relative to edge length
+		for k := range q.Z {
+			b0, b1 := (q.Z[k]-a0)*cmplx.Conj(u), (q.Z[(k+1)%len(q.Z)]-a0)*cmplx.Conj(u) // local coordinates, edge a along the real axis
+			if math.Abs(imag(b0)) > etol || math.Abs(imag(b1)) > etol {
+				continue // not collinear
+			}
+			if o := math.Min(l, math.Max(real(b0), real(b1))) - math.Max(0., math.Min(real(b0), real(b1))); o > etol && o/l > ox {
+				jx, ox = j, o/l
+			}
+		}
+	}
+	return jx
+}
+
+// connectFaces maps the connections of prism pid to its faces, ordered [laterals]-bottom-top, summing the fluxes q of
+// neighbours sharing a face. Connectivity holds one neighbour per face, faces with several neighbours are returned
+// separately, by face, with their fluxes.
+func connectFaces(prsms map[int]*Prism, pid int, to []int, q []float64) ([]int, []float64, map[int][]faceNeighbour, error) {
+	p := prsms[pid]
+	nf := len(p.Z) + 2
+	conn, flx := make([]int, nf), make([]float64, nf)
+	for j := range conn {
+		conn[j] = -1
+	}
+	var shrd map[int][]faceNeighbour
+	for k, c := range to {
+		pc, ok := prsms[c]
+		if !ok {
+			return nil, nil, nil, fmt.Errorf("prism %d connected to unknown prism %d", pid, c)
+		}
+		j := faceOf(p, pc)
+		if j < 0 {
+			return nil, nil, nil, fmt.Errorf("prism %d shares no face with connected prism %d", pid, c)
+		}
+		if conn[j] >= 0 {
+			if shrd == nil {
+				shrd = make(map[int][]faceNeighbour)
+			}
+			if len(shrd[j]) == 0 {
+				k0 := slices.Index(to, conn[j])
+				shrd[j] = []faceNeighbour{{pid: conn[j], q: q[k0]}}
+			}
+			shrd[j] = append(shrd[j], faceNeighbour{pid: c, q: q[k]})
+		} else {
+			conn[j] = c
+		}
+		flx[j] += q[k]
+	}
+	return conn, flx, shrd, nil
+}
+
+// faceNeighbours returns the prisms sharing face j of prism pid, with their fluxes flx and shared faces shrd
+// (those of the steady-state flow field, or of a time step)
+func (d *Domain) faceNeighbours(pid, j int, flx map[int][]float64, shrd map[int]map[int][]faceNeighbour) []faceNeighbour {
+	if s, ok := shrd[pid][j]; ok {
+		return s
+	}
+	c := -1
+	if j < len(d.conn[pid]) {
+		c = d.conn[pid][j]
+	}
+	return []faceNeighbour{{pid: c, q: flx[pid][j]}}
+}
+
+// neighbours returns the prisms connected to prism pid, including every prism of shared faces
+func (d *Domain) neighbours(pid int) []int {
+	if len(d.shrd[pid]) == 0 {
+		return d.conn[pid]
+	}
+	c := slices.Clone(d.conn[pid])
+	for _, s := range d.shrd[pid] {
+		for _, n := range s {
+			if !slices.Contains(c, n.pid) {
+				c = append(c, n.pid)
+			}
+		}
+	}
+	return c
+}
@@ -0,0 +1,39 @@
+package ptrack
+
+import "testing"
+
+func TestConnectFacesQuadtree(t *testing.T) {
+	g := quadtreeGrid()
+	prsms := make(map[int]*Prism, len(g.cells))
+	for c, iv := range g.cells {
+		z := make([]complex128, len(iv))
+		for j, v := range iv {
+			z[j] = complex(g.verts[v][0], g.verts[v][1])
+		}
+		var p Prism
+		if err := p.New(z, g.top, g.bot, g.top, 0., .3); err != nil {
+			t.Fatal(err)
+		}
+		prsms[c] = &p
+	}
+	if j := faceOf(prsms[1], prsms[0]); j != 0 {
+		t.Errorf("cell 1 shares face %d with cell 0, want its left face (0)", j)
+	}
+	if j := faceOf(prsms[2], prsms[1]); j != 3 {
+		t.Errorf("cell 2 shares face %d with cell 1, want its bottom edge (3)", j)
+	}
+
+	conn, flx, shrd, err := connectFaces(prsms, 0, []int{1, 2}, []float64{-.6, -.4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if conn[2] != 1 || flx[2] != -1. {
+		t.Errorf("right face holds cell %d with flux %g, want the first neighbour (1) and the summed flux (-1)", conn[2], flx[2])
+	}
+	if s := shrd[2]; len(s) != 2 || s[0] != (faceNeighbour{1, -.6}) || s[1] != (faceNeighbour{2, -.4}) {
+		t.Errorf("right face shared with %v, want cells 1 and 2 with their fluxes", s)
+	}
+	if len(shrd) != 1 {
+		t.Errorf("%d shared faces, want 1", len(shrd))
+	}
+}
@@ -2,22 +2,21 @@ package ptrack
 
 import (
 	"fmt"
-	"log"
 
 	"github.com/maseology/goHydro/mesh"
 )
 
 // currently reads only 1 state.
-func ReadHSTRAT(hstratFP string) (Domain, *mesh.Slice) {
+func ReadHSTRAT(hstratFP string) (Domain, *mesh.Slice, error) {
 
 	h, err := mesh.ReadHSTRAT(hstratFP, true)
 	if err != nil {
-		panic(err)
+		return Domain{}, nil, fmt.Errorf("ReadHSTRAT: %w", err)
 	}
 
 	// get prisms
 	fmt.Printf(" Model structure read: %s nodes, %s elements, %d layers\n", big(h.Nn), big(h.Ne), h.Nly)
-	pset := func() map[int]*Prism {
+	pset, err := func() (map[int]*Prism, error) {
 		prsms := make(map[int]*Prism, h.Ne)
 		//  p1--p2
 		//   | /          clockwise, left-top-right-bottom
@@ -46,10 +45,15 @@ func ReadHSTRAT(hstratFP string) (Domain, *mesh.Slice) {
 				Bn:  top / fnh,
 				Tn:  0.,
 			}
-			prsms[eid].computeArea()
+			if err := prsms[eid].computeArea(); err != nil {
+				return nil, fmt.Errorf("element %d: %w", eid, err)
+			}
 		}
-		return prsms
+		return prsms, nil
 	}()
+	if err != nil {
+		return Domain{}, nil, fmt.Errorf("ReadHSTRAT: %w", err)
+	}
 
 	// get saturation
 	if len(h.Nh) == h.Nn {
@@ -68,7 +72,7 @@ func ReadHSTRAT(hstratFP string) (Domain, *mesh.Slice) {
 			}
 		}
 	} else {
-		log.Fatalln("ReadHSTRAT todo")
+		return Domain{}, nil, fmt.Errorf("ReadHSTRAT: %d nodal heads read, expecting %d", len(h.Nh), h.Nn)
 	}
 
 	// get flux
@@ -88,16 +92,16 @@ func ReadHSTRAT(hstratFP string) (Domain, *mesh.Slice) {
 			pflx[eid] = q
 		}
 	} else {
-		log.Fatalln("ReadHSTRAT todo")
+		return Domain{}, nil, fmt.Errorf("ReadHSTRAT: %d nodal velocities read, expecting %d", len(h.Vxyz), h.Nn)
 	}
 
 	fmt.Printf("  results collected at end of simulation: %s vectors and %s scalars collected\n", big(len(pflx)), big(len(h.Nh)))
 
 	var d Domain
+	d.Nly = h.Nly // sizes the spatial index
 	d.New(pset, h.BuildElementalConnectivity(false), pflx, nil)
-	d.Nly = h.Nly
 	d.Minthick = h.MinThick
-	return d, h.TopSlice()
+	return d, h.TopSlice(), nil
 }
 
 // func checkConsistentOrder(zs []complex128) {
@@ -0,0 +1,291 @@
+package ptrack
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// FlowGraph is the directed prism-to-prism flow graph of a domain, used to route water volumetrically
+// without releasing particles (FlowSource; Foley and Black, 2017).
+// Prisms are grouped into strongly connected components, ordered from upstream to downstream;
+// components of more than one prism hold flow cycles and are solved iteratively.
+type FlowGraph struct {
+	pids     []int                   // sorted prism IDs
+	dn, up   map[int]map[int]float64 // downstream and upstream edges, with their volumetric flux
+	ein, eou map[int]float64         // external inflow (boundary faces, sources, imbalance) and outflow (boundary faces, sinks, imbalance)
+	q        map[int]float64         // prism throughflow
+	scc      [][]int                 // strongly connected components, upstream to downstream
+}
+
+const (
+	flowGraphTol   = 1e-10 // convergence criterion for cycles
+	flowGraphMaxIt = 10000 // maximum iterations for cycles
+)
+
+// FlowGraph builds the directed flow graph from the prism connectivity and (steady-state, or first time step) fluxes.
+// Flux across a face without a neighbouring prism, well/boundary fluxes, and any mass-balance residual
+// are treated as exchanges external to the graph.
+func (d *Domain) FlowGraph() (*FlowGraph, error) {
+	if len(d.steps) > 0 {
+		return d.FlowGraphAt(0)
+	}
+	return d.flowGraph(d.flx, d.shrd, d.qw, nil)
+}
+
+// FlowGraphAt builds the directed flow graph from the fluxes and storage of time step k of a transient domain (see TimeSteps)
+func (d *Domain) FlowGraphAt(k int) (*FlowGraph, error) {
+	if k < 0 || k >= len(d.steps) {
+		return nil, fmt.Errorf("FlowGraphAt: time step %d out of range, domain has %d", k, len(d.steps))
+	}
+	s := d.steps[k]
+	qss := make(map[int]float64, len(s.prsms))
+	for i, q := range s.prsms {
+		qss[i] = q.Qss
+	}
+	return d.flowGraph(s.flx, s.shrd, s.qw, qss)
+}
+
+// flowGraph builds the flow graph from fluxes flx (and shared faces shrd), point fluxes qw and specific storage qss
+// (positive released). Storage counts toward prism throughflow, being neither a source nor a sink of the graph.
+func (d *Domain) flowGraph(flx map[int][]float64, shrd map[int]map[int][]faceNeighbour, qw, qss map[int]float64) (*FlowGraph, error) {
+	g := FlowGraph{
+		pids: make([]int, 0, len(d.prsms)),
+		dn:   make(map[int]map[int]float64, len(d.prsms)),
+		up:   make(map[int]map[int]float64, len(d.prsms)),
+		ein:  make(map[int]float64, len(d.prsms)),
+		eou:  make(map[int]float64, len(d.prsms)),
+		q:    make(map[int]float64, len(d.prsms)),
+	}
+	for i := range d.prsms {
+		g.pids = append(g.pids, i)
+		g.dn[i], g.up[i] = make(map[int]float64), make(map[int]float64)
+	}
+	sort.Ints(g.pids)
+
+	// edges are taken from the outflow side of every face, such that each is counted once
+	for _, i := range g.pids {
+		f, ok := flx[i]
+		if !ok {
+			return nil, fmt.Errorf("FlowGraph: prism %d has no fluxes", i)
+		}
+		for j := range f {
+			for _, n := range d.faceNeighbours(i, j, flx, shrd) {
+				c, v := n.pid, n.q
+				if _, ok := d.prsms[c]; !ok || c == i {
+					if v > 0. {
+						g.ein[i] += v
+					} else {
+						g.eou[i] -= v
+					}
+					continue
+				}
+				if v < 0. {
+					g.dn[i][c] -= v
+					g.up[c][i] -= v
+				}
+			}
+		}
+		if qw := qw[i]; qw > 0. {
+			g.ein[i] += qw
+		} else {
+			g.eou[i] -= qw
+		}
+	}
+
+	// close the mass balance of every prism
+	for _, i := range g.pids {
+		in, out := g.ein[i], g.eou[i]
+		if v := qss[i]; v > 0. {
+			in += v // released from storage
+		} else {
+			out -= v
+		}
+		for _, v := range g.up[i] {
+			in += v
+		}
+		for _, v := range g.dn[i] {
+			out += v
+		}
+		if r := in - out; r > 0. {
+			g.eou[i] += r
+		} else {
+			g.ein[i] -= r
+		}
+		g.q[i] = math.Max(in, out)
+	}
+
+	g.components()
+	return &g, nil
+}
+
+// components finds the strongly connected components of the graph (Tarjan's algorithm, iterative),
+// ordered from upstream to downstream
+func (g *FlowGraph) components() {
+	idx, low, onstk := make(map[int]int, len(g.pids)), make(map[int]int, len(g.pids)), make(map[int]bool)
+	var stk []int
+	var scc [][]int
+	n := 0
+
+	type frame struct {
+		v  int
+		ws []int
+		k  int
+	}
+	succ := func(v int) []int {
+		ws := make([]int, 0, len(g.dn[v]))
+		for w := range g.dn[v] {
+			ws = append(ws, w)
+		}
+		sort.Ints(ws)
+		return ws
+	}
+	for _, s := range g.pids {
+		if _, ok := idx[s]; ok {
+			continue
+		}
+		idx[s], low[s] = n, n
+		n++
+		stk = append(stk, s)
+		onstk[s] = true
+		call := []frame{{s, succ(s), 0}}
+		for len(call) > 0 {
+			f := &call[len(call)-1]
+			if f.k < len(f.ws) {
+				w := f.ws[f.k]
+				f.k++
+				if _, ok := idx[w]; !ok {
+					idx[w], low[w] = n, n
+					n++
+					stk = append(stk, w)
+					onstk[w] = true
+					call = append(call, frame{w, succ(w), 0})
+				} else if onstk[w] {
+					low[f.v] = min(low[f.v], idx[w])
+				}
+				continue
+			}
+			v := f.v
+			call = call[:len(call)-1]
+			if len(call) > 0 {
+				u := call[len(call)-1].v
+				low[u] = min(low[u], low[v])
+			}
+			if low[v] == idx[v] {
+				var c []int
+				for {
+					w := stk[len(stk)-1]
+					stk = stk[:len(stk)-1]
+					onstk[w] = false
+					c = append(c, w)
+					if w == v {
+						break
+					}
+				}
+				sort.Ints(c)
+				scc = append(scc, c)
+			}
+		}
+	}
+
+	// Tarjan returns components downstream first
+	for i, j := 0, len(scc)-1; i < j; i, j = i+1, j-1 {
+		scc[i], scc[j] = scc[j], scc[i]
+	}
+	g.scc = scc
+}
+
+// Cycles returns the groups of prisms among which flow circulates
+func (g *FlowGraph) Cycles() [][]int {
+	var o [][]int
+	for _, c := range g.scc {
+		if len(c) > 1 {
+			o = append(o, c)
+		}
+	}
+	return o
+}
+
+// Throughflow returns the volumetric flux passing through prism pid
+func (g *FlowGraph) Throughflow(pid int) float64 { return g.q[pid] }
+
+// CaptureFractions returns, for every prism, the fraction of its water that eventually discharges to the given sink prisms.
+// The external outflow of a sink prism (well, boundary, etc.) is captured; sink prisms having no external outflow capture all their throughflow.
+func (g *FlowGraph) CaptureFractions(sinks ...int) map[int]float64 {
+	issnk := make(map[int]bool, len(sinks))
+	for _, i := range sinks {
+		issnk[i] = true
+	}
+	own := func(i int) (float64, bool) {
+		if !issnk[i] {
+			return 0., false
+		}
+		if g.eou[i] <= 0. {
+			return 1., true
+		}
+		return g.eou[i], false
+	}
+	// downstream prisms are solved first
+	ord := make([][]int, len(g.scc))
+	for k, c := range g.scc {
+		ord[len(g.scc)-1-k] = c
+	}
+	return g.route(ord, g.dn, own)
+}
+
+// SourceFractions returns, for every prism, the fraction of its water that originates from the given source prisms.
+// The external inflow of a source prism (recharge, injection, boundary, etc.) is tracked; source prisms having no external inflow contribute all their throughflow.
+func (g *FlowGraph) SourceFractions(sources ...int) map[int]float64 {
+	issrc := make(map[int]bool, len(sources))
+	for _, i := range sources {
+		issrc[i] = true
+	}
+	own := func(i int) (float64, bool) {
+		if !issrc[i] {
+			return 0., false
+		}
+		if g.ein[i] <= 0. {
+			return 1., true
+		}
+		return g.ein[i], false
+	}
+	return g.route(g.scc, g.up, own)
+}
+
+// route solves f(i) = (own(i) + sum_j e(i,j) f(j)) / Q(i) over components in order ord, such that neighbours j in e are solved first.
+// own returns either the tagged external flux of a prism or, when fixed is true, its fraction directly.
+func (g *FlowGraph) route(ord [][]int, e map[int]map[int]float64, own func(int) (float64, bool)) map[int]float64 {
+	f := make(map[int]float64, len(g.pids))
+	eval := func(i int) float64 {
+		s, fixed := own(i)
+		if fixed {
+			return s
+		}
+		if g.q[i] <= 0. {
+			return 0.
+		}
+		for j, v := range e[i] {
+			s += v * f[j]
+		}
+		return math.Min(s/g.q[i], 1.)
+	}
+	for _, c := range ord {
+		if len(c) == 1 {
+			f[c[0]] = eval(c[0])
+			continue
+		}
+		// flow cycle: Gauss-Seidel iteration, upstream components already solved
+		for it := 0; it < flowGraphMaxIt; it++ {
+			dx := 0.
+			for _, i := range c {
+				v := eval(i)
+				dx = math.Max(dx, math.Abs(v-f[i]))
+				f[i] = v
+			}
+			if dx < flowGraphTol {
+				break
+			}
+		}
+	}
+	return f
+}
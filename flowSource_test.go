@@ -0,0 +1,79 @@
+package ptrack
+
+import (
+	"math"
+	"slices"
+	"testing"
+)
+
+// circulationDomain is a 2x2 block of prisms (0 lower-left, 1 lower-right, 2 upper-left, 3 upper-right) where water
+// entering prism 0 circulates 0-1-3-2-0, leaving the domain from prisms 3 (.6) and 2 (.4)
+func circulationDomain(t *testing.T) *Domain {
+	prsms, conn := make(map[int]*Prism, 4), make(map[int][]int, 4)
+	for i := range 4 {
+		x0, y0 := float64(i%2), float64(i/2)
+		var p Prism
+		if err := p.New([]complex128{complex(x0, y0), complex(x0, y0+1), complex(x0+1, y0+1), complex(x0+1, y0)}, 1., 0., 1., 0., .3); err != nil {
+			t.Fatal(err)
+		}
+		prsms[i] = &p
+	}
+	conn[0], conn[1] = []int{-1, 2, 1, -1, -1, -1}, []int{0, 3, -1, -1, -1, -1} // left-up-right-down-bottom-top
+	conn[2], conn[3] = []int{-1, -1, 3, 0, -1, -1}, []int{2, -1, -1, 1, -1, -1}
+	flx := map[int][]float64{
+		0: {1., 2., -3., 0., 0., 0.},
+		1: {3., -3., 0., 0., 0., 0.},
+		2: {-.4, 0., 2.4, -2., 0., 0.},
+		3: {-2.4, 0., -.6, 3., 0., 0.},
+	}
+	var d Domain
+	d.New(prsms, conn, flx, nil)
+	return &d
+}
+
+func TestFlowGraphCycle(t *testing.T) {
+	g, err := circulationDomain(t).FlowGraph()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c := g.Cycles(); len(c) != 1 || !slices.Equal(c[0], []int{0, 1, 2, 3}) {
+		t.Errorf("cycles %v, want the 4 prisms circulating", c)
+	}
+	if q := g.Throughflow(1); q != 3. {
+		t.Errorf("prism 1 throughflow %g, want 3", q)
+	}
+	f3, f2 := g.CaptureFractions(3), g.CaptureFractions(2)
+	for i := range 4 {
+		if s := f3[i] + f2[i]; math.Abs(s-1.) > 1e-8 {
+			t.Errorf("prism %d: capture fractions %g + %g, want a sum of 1", i, f3[i], f2[i])
+		}
+	}
+	if math.Abs(f3[0]-.6) > 1e-8 {
+		t.Errorf("prism 0 capture by prism 3 %g, want .6", f3[0])
+	}
+	for i, f := range g.SourceFractions(0) {
+		if math.Abs(f-1.) > 1e-8 {
+			t.Errorf("prism %d source fraction %g, want all water from prism 0", i, f)
+		}
+	}
+}
+
+func TestFlowGraphAtStorage(t *testing.T) {
+	d := rowDomain([]float64{1., 1., .5, .5}, nil) // prism 1 takes .5 into storage
+	if err := d.AddTimeStep(1, 1, 1., d.flx, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.SetStorage(map[int]float64{1: -.5}, nil); err != nil {
+		t.Fatal(err)
+	}
+	g, err := d.FlowGraphAt(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g.eou[1] != 0. || g.ein[1] != 0. || g.Throughflow(1) != 1. {
+		t.Errorf("prism 1 external inflow %g, outflow %g and throughflow %g, want storage held apart from the graph's sources and sinks", g.ein[1], g.eou[1], g.Throughflow(1))
+	}
+	if f := g.SourceFractions(0)[2]; math.Abs(f-1.) > 1e-12 {
+		t.Errorf("prism 2 source fraction %g, want all water from prism 0", f)
+	}
+}
@@ -3,7 +3,6 @@ package ptrack
 import (
 	"encoding/binary"
 	"fmt"
-	"log"
 	"strings"
 
 	"github.com/maseology/goHydro/grid"
@@ -11,31 +10,34 @@ import (
 )
 
 // currently needs an accompanying .gdef; also not reading wells
-func ReadFLX(hstratFP, flxFP string) (Domain, *grid.Definition) {
+func ReadFLX(hstratFP, flxFP string) (Domain, *grid.Definition, error) {
 
 	// get geometry
 	gd, err := grid.ReadGDEF(mmio.RemoveExtension(hstratFP)+".gdef", true)
 	if err != nil {
 		gd, err = grid.ReadGDEF(mmio.RemoveExtension(flxFP)+".gdef", true)
 		if err != nil {
-			log.Fatalf(" ReadFLX gdef read error: %v", err)
+			return Domain{}, nil, fmt.Errorf("ReadFLX gdef read error: %w", err)
 		}
 	}
 	fmt.Printf(" GDEF read: %s cells (%d rows, %d columns), %s actives\n", big(gd.Ncells()), gd.Nrow, gd.Ncol, big(gd.Nact))
 	hstrat, err := grid.ReadHSTRAT(hstratFP, true)
 	if err != nil {
-		log.Fatalf(" ReadFLX hstrat read error: %v", err)
+		return Domain{}, nil, fmt.Errorf("ReadFLX hstrat read error: %w", err)
 	}
 	if len(hstrat.Cells) != gd.Nact*hstrat.Nlay {
 		if len(hstrat.Cells) != gd.Nrow*gd.Ncol*hstrat.Nlay {
-			log.Fatalf(" ReadFLX hstrat count error, nact = %d, nlay = %d, nhs = %d", gd.Nact, hstrat.Nlay, len(hstrat.Cells))
+			return Domain{}, nil, fmt.Errorf("ReadFLX hstrat count error, nact = %d, nlay = %d, nhs = %d", gd.Nact, hstrat.Nlay, len(hstrat.Cells))
 		}
 		print("")
 	}
 	fmt.Printf(" HSTRAT read: %s cells (%d layers), %s elements per layer\n\n", big(len(hstrat.Cells)), hstrat.Nlay, big(len(hstrat.Cells)/hstrat.Nlay))
 
 	// read flux
-	qR, qF, qL, nlay := readMF2005(flxFP, true)
+	qR, qF, qL, nlay, err := readMF2005(flxFP, true)
+	if err != nil {
+		return Domain{}, nil, fmt.Errorf("ReadFLX: %w", err)
+	}
 	// if nlay != hstrat.Nlay {
 	// 	log.Fatalf(" ReadFLX hstrat layer error: %v", err)
 	// }
@@ -70,7 +72,7 @@ func ReadFLX(hstratFP, flxFP string) (Domain, *grid.Definition) {
 	}()
 
 	fmt.Println("\n building prisms..")
-	pset := func() map[int]*Prism {
+	pset, err := func() (map[int]*Prism, error) {
 		prsms := make(map[int]*Prism, gd.Nact*nlay)
 		// p1---p2   y       0---nc
 		//  | c |    |       |       clockwise, left-top-right-bottom
@@ -110,11 +112,16 @@ func ReadFLX(hstratFP, flxFP string) (Domain, *grid.Definition) {
 					Bn:  bn,
 					Tn:  0.,
 				}
-				prsms[lcid].computeArea()
+				if err := prsms[lcid].computeArea(); err != nil {
+					return nil, fmt.Errorf("cell %d: %w", lcid, err)
+				}
 			}
 		}
-		return prsms
+		return prsms, nil
 	}()
+	if err != nil {
+		return Domain{}, nil, fmt.Errorf("ReadFLX: %w", err)
+	}
 
 	// get flux
 	fmt.Println(" collecting flux..")
@@ -140,12 +147,16 @@ func ReadFLX(hstratFP, flxFP string) (Domain, *grid.Definition) {
 	}()
 
 	var d Domain
+	d.Nly = hstrat.Nlay // sizes the spatial index
 	d.New(pset, conn, pflx, nil)
 	d.Minthick = hstrat.MinThick
-	return d, gd
+	return d, gd, nil
 }
 
-func readMF2005(fp string, prnt bool) (qRight, qFront, qLower map[int]float64, nlay int) {
+func readMF2005(fp string, prnt bool) (qRight, qFront, qLower map[int]float64, nlay int, err error) {
+	if _, ok := mmio.FileExists(fp); !ok {
+		return nil, nil, nil, 0, fmt.Errorf("readMF2005: file %s cannot be found", fp)
+	}
 	bflx := mmio.OpenBinary(fp)
 	dat1D := make(map[string]map[int]float64)
 	dat2D := make(map[string]map[int]map[int]float64)
@@ -252,27 +263,29 @@ func readMF2005(fp string, prnt bool) (qRight, qFront, qLower map[int]float64, n
 
 		case 6: // Read text identifiers, auxiliary text labels, and list of information.
 			a := cbcAuxReader{}
-			a.cbcAuxRead(bflx)
+			if err := a.cbcAuxRead(bflx); err != nil {
+				return nil, nil, nil, 0, fmt.Errorf("readMF2005 %s: %w", txt, err)
+			}
 			auxtext := make([]string, int(a.NDAT))
 			for i := 0; i < int(a.NDAT)-1; i++ {
 				var b1 [16]byte
 				if err := binary.Read(bflx, binary.LittleEndian, &b1); err != nil {
-					log.Fatalln("Fatal error: AUXTEXT read failed: ", err)
+					return nil, nil, nil, 0, fmt.Errorf("readMF2005 %s AUXTEXT read failed: %w", txt, err)
 				}
 				auxtext[i] = string(b1[:])
 			}
 			var nlist int32
 			if err := binary.Read(bflx, binary.LittleEndian, &nlist); err != nil {
-				log.Fatalln("Fatal error: NLIST read failed: ", err)
+				return nil, nil, nil, 0, fmt.Errorf("readMF2005 %s NLIST read failed: %w", txt, err)
 			}
 			d2D := make(map[int]map[int]float64)
 			for i := 0; i < int(nlist); i++ {
 				var id1, id2 int32
 				if err := binary.Read(bflx, binary.LittleEndian, &id1); err != nil {
-					log.Fatalln("Fatal error: ID1 read failed: ", err)
+					return nil, nil, nil, 0, fmt.Errorf("readMF2005 %s ID1 read failed: %w", txt, err)
 				}
 				if err := binary.Read(bflx, binary.LittleEndian, &id2); err != nil {
-					log.Fatalln("Fatal error: ID2 read failed: ", err)
+					return nil, nil, nil, 0, fmt.Errorf("readMF2005 %s ID2 read failed: %w", txt, err)
 				}
 				m1 := make(map[int]float64)
 				for j := 0; j < int(a.NDAT); j++ {
@@ -282,7 +295,7 @@ func readMF2005(fp string, prnt bool) (qRight, qFront, qLower map[int]float64, n
 			}
 			dat2D[txt] = d2D
 		default:
-			log.Fatalf("MODFLOW CBC read error: IMETH=%d not supported", ICODE)
+			return nil, nil, nil, 0, fmt.Errorf("MODFLOW CBC read error: IMETH=%d not supported (%s)", ICODE, txt)
 		}
 	}
 
@@ -298,5 +311,5 @@ func readMF2005(fp string, prnt bool) (qRight, qFront, qLower map[int]float64, n
 		}
 	}
 
-	return dat1D["FLOW RIGHT FACE"], dat1D["FLOW FRONT FACE"], dat1D["FLOW LOWER FACE"], nlay
+	return dat1D["FLOW RIGHT FACE"], dat1D["FLOW FRONT FACE"], dat1D["FLOW LOWER FACE"], nlay, nil
 }
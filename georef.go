@@ -0,0 +1,83 @@
+package ptrack
+
+import (
+	"fmt"
+	"math"
+	"math/cmplx"
+)
+
+// Transform georeferences the model: model (grid) coordinates are rotated counter-clockwise about the model origin,
+// then offset to the world coordinates of the origin (i.e., XORIGIN, YORIGIN and ANGROT of a MODFLOW 6 grid)
+type Transform struct {
+	X0, Y0 float64 // world coordinates of the model origin
+	Rot    float64 // counter-clockwise rotation (degrees)
+}
+
+func (t Transform) rotation() complex128 { return cmplx.Rect(1., t.Rot*math.Pi/180.) }
+
+// ToWorld converts model coordinates (x,y) to world coordinates
+func (t Transform) ToWorld(x, y float64) (float64, float64) {
+	z := complex(x, y)*t.rotation() + complex(t.X0, t.Y0)
+	return real(z), imag(z)
+}
+
+// ToModel converts world coordinates (x,y) to model coordinates
+func (t Transform) ToModel(x, y float64) (float64, float64) {
+	z := (complex(x, y) - complex(t.X0, t.Y0)) / t.rotation()
+	return real(z), imag(z)
+}
+
+// Coordinates sets the coordinate system of particle input, Locate, tracked pathlines and exported results
+type Coordinates int
+
+const (
+	WorldCoordinates Coordinates = iota // georeferenced using the domain transform (default)
+	ModelCoordinates                    // model (grid) coordinates, as the prisms are held
+)
+
+// SetTransform sets the transform georeferencing the domain. Prisms are unchanged, remaining in model coordinates.
+func (d *Domain) SetTransform(t Transform) { d.xf = t }
+
+// Transform returns the transform georeferencing the domain (identity when not georeferenced)
+func (d *Domain) Transform() Transform { return d.xf }
+
+// SetCoordinates sets the coordinate system of particle input (particles, wells), Locate, tracked pathlines and every exporter
+func (d *Domain) SetCoordinates(c Coordinates) error {
+	switch c {
+	case WorldCoordinates, ModelCoordinates:
+	default:
+		return fmt.Errorf("SetCoordinates: unknown coordinate system %d", c)
+	}
+	d.crs = c
+	return nil
+}
+
+// toModel converts input coordinates (x,y) to model coordinates
+func (d *Domain) toModel(x, y float64) (float64, float64) {
+	if d.crs == ModelCoordinates {
+		return x, y
+	}
+	return d.xf.ToModel(x, y)
+}
+
+// fromModel converts model coordinates (x,y) to the output coordinate system
+func (d *Domain) fromModel(x, y float64) (float64, float64) {
+	if d.crs == ModelCoordinates {
+		return x, y
+	}
+	return d.xf.ToWorld(x, y)
+}
+
+// pathlinesFromModel converts tracked pathlines pl and the coordinates of their capturing sinks to the output coordinate system, in place
+func (d *Domain) pathlinesFromModel(pl [][]Particle, term []Termination) {
+	for _, pln := range pl {
+		for j := range pln {
+			pln[j].X, pln[j].Y = d.fromModel(pln[j].X, pln[j].Y)
+		}
+	}
+	for _, t := range term {
+		if t.Sink != nil { // a copy of the domain's well
+			t.Sink.X, t.Sink.Y = d.fromModel(t.Sink.X, t.Sink.Y)
+		}
+	}
+}
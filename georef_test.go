@@ -0,0 +1,74 @@
+package ptrack
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestTrackParticlesWorldCoordinates(t *testing.T) {
+	d := rowDomain([]float64{1., 1., 1., 1., 1.}, nil)
+	if err := d.MakeVector(); err != nil {
+		t.Fatal(err)
+	}
+	d.SetTransform(Transform{X0: 100., Y0: 200., Rot: 90.})
+	if err := d.SetCoordinates(ModelCoordinates); err != nil {
+		t.Fatal(err)
+	}
+	plm, _, _, err := d.TrackParticles(Particles{{X: .5, Y: .5, Z: .5}}, TrackOptions{}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := d.SetCoordinates(WorldCoordinates); err != nil {
+		t.Fatal(err)
+	}
+	x0, y0 := d.Transform().ToWorld(.5, .5)
+	plw, _, _, err := d.TrackParticles(Particles{{X: x0, Y: y0, Z: .5}}, TrackOptions{}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(plw[0]) != len(plm[0]) || len(plm[0]) < 2 {
+		t.Fatalf("%d world vertices, %d model vertices", len(plw[0]), len(plm[0]))
+	}
+	if p := plw[0][0]; math.Abs(p.X-x0) > 1e-9 || math.Abs(p.Y-y0) > 1e-9 {
+		t.Errorf("first vertex (%v,%v), want the world release point (%v,%v)", p.X, p.Y, x0, y0)
+	}
+	for j, p := range plw[0] {
+		xw, yw := d.Transform().ToWorld(plm[0][j].X, plm[0][j].Y)
+		if math.Abs(p.X-xw) > 1e-9 || math.Abs(p.Y-yw) > 1e-9 {
+			t.Errorf("vertex %d (%v,%v), want world coordinates (%v,%v)", j, p.X, p.Y, xw, yw)
+		}
+	}
+}
+
+func TestExportVTKRotatesVelocity(t *testing.T) {
+	d := rowDomain([]float64{1., 1., 1.}, nil)
+	if err := d.MakeVector(); err != nil {
+		t.Fatal(err)
+	}
+	d.SetTransform(Transform{Rot: 90.})
+	fp := filepath.Join(t.TempDir(), "d.vtk")
+	if err := d.ExportVTK(fp, 1.); err != nil {
+		t.Fatal(err)
+	}
+	b, err := os.ReadFile(fp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tag := []byte("VECTORS Vcentroid double\n")
+	i := bytes.Index(b, tag)
+	if i < 0 {
+		t.Fatal("centroid velocities not written")
+	}
+	v := make([]float64, 3)
+	if err := binary.Read(bytes.NewReader(b[i+len(tag):]), binary.BigEndian, v); err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(v[0]) > 1e-9 || v[1] <= 0. { // flow along model x points north once rotated
+		t.Errorf("centroid velocity (%v,%v), want (0,+)", v[0], v[1])
+	}
+}
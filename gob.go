@@ -3,12 +3,14 @@ package ptrack
 import (
 	"encoding/gob"
 	"fmt"
+	"io"
 	"os"
 
 	"github.com/maseology/mmio"
 )
 
-func ExportPathlinesGob(fp string, pl [][]Particle) error {
+// ExportPathlinesGob saves pathlines, followed by their terminations (optional), to a gob file
+func ExportPathlinesGob(fp string, pl [][]Particle, term []Termination) error {
 	f, err := os.Create(fp)
 	if err != nil {
 		return err
@@ -18,24 +20,34 @@ func ExportPathlinesGob(fp string, pl [][]Particle) error {
 	if err != nil {
 		return err
 	}
+	if term != nil {
+		if err = enc.Encode(term); err != nil {
+			return err
+		}
+	}
 	f.Close()
 	return nil
 }
 
-func LoadPathlinesGOB(fp string) ([][]Particle, int64, error) {
+// LoadPathlinesGOB loads pathlines saved using ExportPathlinesGob. Terminations are nil for files saved without them.
+func LoadPathlinesGOB(fp string) ([][]Particle, []Termination, int64, error) {
 	if s, ok := mmio.FileExists(fp); ok {
 		var d [][]Particle
 		f, err := os.Open(fp)
 		if err != nil {
-			return nil, -1, err
+			return nil, nil, -1, err
 		}
+		defer f.Close()
 		enc := gob.NewDecoder(f)
 		err = enc.Decode(&d)
 		if err != nil {
-			return nil, -1, err
+			return nil, nil, -1, err
+		}
+		var term []Termination
+		if err = enc.Decode(&term); err != nil && err != io.EOF {
+			return nil, nil, -1, err
 		}
-		f.Close()
-		return d, s, nil
+		return d, term, s, nil
 	}
-	return nil, -1, fmt.Errorf("file %s cannot be found", fp)
+	return nil, nil, -1, fmt.Errorf("file %s cannot be found", fp)
 }
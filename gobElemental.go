@@ -6,6 +6,7 @@ import (
 	"os"
 )
 
+// ExportMeshNetworkGob saves pathlines pl, as returned from tracking, as a network of vertices in the domain's coordinate system
 func (d *Domain) ExportMeshNetworkGob(fp string, pl [][]Particle, nv int) error {
 
 	nds := make([]Vert, 0, nv)
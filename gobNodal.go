@@ -14,6 +14,8 @@ type Vert struct {
 	VertID, PathID, PrsmID, CellID, Layer, Order, USid, DSid int
 }
 
+// ExportGridNetworkGob saves pathlines pl, as returned from tracking, as a network of vertices in the domain's coordinate
+// system, grid definition gd being given in the same coordinate system
 func (d *Domain) ExportGridNetworkGob(fp string, gd *grid.Definition, pl [][]Particle) error {
 
 	// build topology
@@ -0,0 +1,24 @@
+package ptrack
+
+// rowDomain returns a single row of unit prisms with lateral flux qx[j] (positive right) entering prism j across its
+// left face, qx[len(qx)-1] leaving the last prism, and point sources/sinks qw by prism
+func rowDomain(qx []float64, qw map[int]float64) *Domain {
+	n := len(qx) - 1
+	prsms, conn, flx := make(map[int]*Prism, n), make(map[int][]int, n), make(map[int][]float64, n)
+	for j := range n {
+		var p Prism
+		x0, x1 := float64(j), float64(j+1)
+		if err := p.New([]complex128{complex(x0, 0), complex(x0, 1), complex(x1, 1), complex(x1, 0)}, 1., 0., 1., 0., .3); err != nil {
+			panic(err)
+		}
+		prsms[j] = &p
+		conn[j] = []int{j - 1, -1, j + 1, -1, -1, -1} // left-up-right-down-bottom-top
+		if j == n-1 {
+			conn[j][2] = -1
+		}
+		flx[j] = []float64{qx[j], 0., -qx[j+1], 0., 0., 0.}
+	}
+	var d Domain
+	d.New(prsms, conn, flx, qw)
+	return &d
+}
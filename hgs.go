@@ -2,14 +2,13 @@ package ptrack
 
 import (
 	"fmt"
-	"log"
 	"strings"
 
 	"github.com/maseology/goHGS/structure"
 )
 
 // currently reads only 1 state.
-func ReadHGS(q_pmFP string) Domain {
+func ReadHGS(q_pmFP string) (Domain, error) {
 	prfx := q_pmFP
 	if strings.Contains(q_pmFP, "o.") {
 		prfx = q_pmFP[:strings.Index(q_pmFP, "o.")]
@@ -17,8 +16,11 @@ func ReadHGS(q_pmFP string) Domain {
 
 	// get prisms
 	h := structure.Read(prfx)
+	if h == nil {
+		return Domain{}, fmt.Errorf("ReadHGS: could not read model structure %s", prfx)
+	}
 	fmt.Printf(" Model structure read: %d nodes, %d elements, %d layers\n", h.Nn, h.Ne, h.Nly)
-	pset := func() map[int]*Prism {
+	pset, err := func() (map[int]*Prism, error) {
 		prsms := make(map[int]*Prism, h.Ne)
 		// p1---p2   y       0---nc
 		//  | c |    |       |       clockwise, left-top-right-bottom
@@ -44,11 +46,16 @@ func ReadHGS(q_pmFP string) Domain {
 				Bn:  top / float64(nh),
 				Tn:  0.,
 			}
-			prsms[eid].computeArea()
+			if err := prsms[eid].computeArea(); err != nil {
+				return nil, fmt.Errorf("element %d: %w", eid, err)
+			}
 		}
 
-		return prsms
+		return prsms, nil
 	}()
+	if err != nil {
+		return Domain{}, fmt.Errorf("ReadHGS: %w", err)
+	}
 
 	// get flux
 	t, v := h.ReadElementalVectors(q_pmFP)
@@ -58,7 +65,7 @@ func ReadHGS(q_pmFP string) Domain {
 			pflx[i] = []float64{float64(vv[0]), float64(vv[1]), float64(vv[2])} // assumed at centroid
 		}
 	} else {
-		log.Fatalln("ReadHGS todo")
+		return Domain{}, fmt.Errorf("ReadHGS: %d elemental vectors read, expecting %d; only elemental fluxes currently supported", len(v), h.Ne)
 	}
 
 	// get saturation
@@ -79,12 +86,12 @@ func ReadHGS(q_pmFP string) Domain {
 			}
 		}
 	} else {
-		log.Fatalln("ReadHGS todo")
+		return Domain{}, fmt.Errorf("ReadHGS: %d nodal heads read, expecting %d", len(s), h.Nn)
 	}
 
 	fmt.Printf(" results collected at time %f: %d vectors and %d scalars collected\n", t, len(v), len(s))
 
 	var d Domain
 	d.New(pset, h.BuildElementalConnectivity(false), pflx, nil)
-	return d
+	return d, nil
 }
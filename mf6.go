@@ -1,13 +1,18 @@
 package ptrack
 
 import (
+	"fmt"
+
 	gomf6 "github.com/maseology/goMF6"
 )
 
-// only works for an "unstructured" quadralinear grid
-func ReadMF6(mf6Prfx string) Domain {
+// ReadMF6 reads an unstructured MODFLOW6 model; connections are mapped to the prism face (polygon edge) they share
+func ReadMF6(mf6Prfx string) (Domain, error) {
 	// read flux
 	mf6 := gomf6.ReadMF6(mf6Prfx)
+	if mf6 == nil {
+		return Domain{}, fmt.Errorf("ReadMF6: could not read %s", mf6Prfx)
+	}
 
 	// geometry
 	nc := len(mf6.Prsms)
@@ -32,54 +37,18 @@ func ReadMF6(mf6Prfx string) Domain {
 		}
 	}
 
-	// flux/transfers
+	// flux/transfers, mapped to the prism faces ([laterals]-bottom-top)
 	conn := make(map[int][]int, nc)
 	pflx := make(map[int][]float64, nc)
-	extentsToDirection := func(pFrom, pTo *Prism) int {
-		cx, cy, cz := pTo.Centroid()
-		if pFrom.ContainsXY(cx, cy) {
-			if cz < pFrom.Bot {
-				return 4 // bottom
-			} else if cz > pFrom.Top {
-				return 5 // top
-			} else {
-				panic("ReadMF6cbc.extentsToDirection: point in prism 001")
-			}
-		}
-
-		if cz < pFrom.Bot || cz > pFrom.Top {
-			panic("ReadMF6cbc.extentsToDirection: non-cardinality")
-		}
-
-		yn, yx, xn, xx := pFrom.getExtentsXY()
-		if cx >= xn && cx <= xx {
-			if cy > yx {
-				return 1 // up
-			} else if cy < yn {
-				return 3 // down
-			} else {
-				panic("ReadMF6cbc.extentsToDirection: point in prism 002")
-			}
-		} else if cy >= yn && cy <= yx {
-			if cx > xx {
-				return 2 // right
-			} else if cx < xn {
-				return 0 // left
-			} else {
-				panic("ReadMF6cbc.extentsToDirection: point in prism 003")
-			}
-		} else {
-			panic("ReadMF6cbc.extentsToDirection: unknown")
-		}
-	}
-
+	shrd := make(map[int]map[int][]faceNeighbour)
 	for nid, mfprsm := range mf6.Prsms {
-		conn[nid] = []int{-1, -1, -1, -1, -1, -1} // convert unstructured flux to left-up-right-down-bottom-top
-		pflx[nid] = make([]float64, 6)
-		for i, cnid := range mfprsm.Conn {
-			pos := extentsToDirection(pset[nid], pset[cnid])
-			conn[nid][pos] = cnid
-			pflx[nid][pos] += mfprsm.Q[i+1]
+		var err error
+		var s map[int][]faceNeighbour
+		if conn[nid], pflx[nid], s, err = connectFaces(pset, nid, mfprsm.Conn, mfprsm.Q[1:len(mfprsm.Conn)+1]); err != nil {
+			return Domain{}, fmt.Errorf("ReadMF6: %w", err)
+		}
+		if s != nil {
+			shrd[nid] = s
 		}
 
 		// // add recharge as top flux (it appears this is not the case with MODPATH)
@@ -93,6 +62,7 @@ func ReadMF6(mf6Prfx string) Domain {
 
 	var d Domain
 	d.New(pset, conn, pflx, mf6.Qw)
+	d.shrd = shrd
 	// d.Minthick = hstrat.MinThick
-	return d
+	return d, nil
 }
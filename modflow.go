@@ -5,122 +5,284 @@ import (
 	"encoding/binary"
 	"fmt"
 	"io"
-	"log"
+	"maps"
+	"math"
+	"slices"
 	"strconv"
 	"strings"
 
 	"github.com/maseology/mmio"
 )
 
-// ReadMODFLOW reads a MODFLOW6 output file
-func ReadMODFLOW(fprfx string) Domain {
-	grbfp := fmt.Sprintf("%s.disu.grb", fprfx)
-	if _, ok := mmio.FileExists(grbfp); !ok {
-		grbfp = fmt.Sprintf("%s.dis.grb", fprfx)
-		if _, ok := mmio.FileExists(grbfp); !ok {
-			panic("ReadMODFLOW: no grb found")
+// ReadMODFLOW reads a MODFLOW6 output file. Prisms are held in model coordinates, the domain being georeferenced
+// by the grid origin and rotation (see Domain.Transform)
+func ReadMODFLOW(fprfx string) (Domain, error) {
+	grbfp := ""
+	for _, ext := range []string{"disu", "disv", "dis"} {
+		if _, ok := mmio.FileExists(fmt.Sprintf("%s.%s.grb", fprfx, ext)); ok {
+			grbfp = fmt.Sprintf("%s.%s.grb", fprfx, ext)
+			break
 		}
 	}
+	if grbfp == "" {
+		return Domain{}, fmt.Errorf("ReadMODFLOW: no grb found for %s", fprfx)
+	}
 
-	pset, conn, jaxr := readGRB(grbfp)
+	pset, conn, jaxr, xf, err := readGRB(grbfp)
+	if err != nil {
+		return Domain{}, fmt.Errorf("ReadMODFLOW: %w", err)
+	}
 	fpcbc := fmt.Sprintf("%s.cbc", fprfx)
 	if _, ok := mmio.FileExists(fpcbc); !ok {
 		fpcbc = fmt.Sprintf("%s.flx", fprfx)
+		if _, ok := mmio.FileExists(fpcbc); !ok {
+			return Domain{}, fmt.Errorf("ReadMODFLOW: no cell-by-cell budget file found for %s", fprfx)
+		}
 	}
-	pflx, pqw := readCBC(fpcbc, jaxr)
-	func() {
-		for m, v := range readDependentVariable(fmt.Sprintf("%s.hds", fprfx)) {
-			fmt.Printf("  DV: %s\n", m)
-			if m == "HEAD" {
-				for i, vv := range v {
-					if vv < pset[i].Top {
-						pset[i].Bn = vv
-					} else {
-						pset[i].Bn = pset[i].Top
-					}
+	steps, err := readCBC(fpcbc, conn, jaxr)
+	if err != nil {
+		return Domain{}, fmt.Errorf("ReadMODFLOW: %w", err)
+	}
+	for _, s := range steps {
+		for _, wls := range s.pwel {
+			for k, w := range wls {
+				if !math.IsNaN(w.X) && !math.IsNaN(w.Y) {
+					wls[k].X, wls[k].Y = xf.ToModel(w.X, w.Y) // auxiliary coordinates given in world coordinates
 				}
 			}
 		}
-	}()
+	}
+	var dvs []dvStep
+	fphds := fmt.Sprintf("%s.hds", fprfx)
+	if _, ok := mmio.FileExists(fphds); ok {
+		if dvs, err = readDependentVariable(fphds); err != nil {
+			return Domain{}, fmt.Errorf("ReadMODFLOW: %w", err)
+		}
+		if len(dvs) > 0 {
+			for m := range dvs[len(dvs)-1].dv {
+				fmt.Printf("  DV: %s\n", m)
+			}
+		}
+	}
+	heads := func(s cbcStep) map[int]float64 {
+		for _, dv := range dvs {
+			if dv.kper == s.kper && dv.kstp == s.kstp {
+				return dv.dv["HEAD"]
+			}
+		}
+		return nil
+	}
+
+	// steady-state, or initial conditions of a transient simulation
+	for i, h := range heads(steps[0]) {
+		if _, ok := pset[i]; !ok {
+			continue // inactive
+		}
+		pset[i].Bn = headToBn(pset[i], h)
+	}
 
 	var d Domain
-	d.New(pset, conn, pflx, pqw)
-	return d
+	d.New(pset, conn, steps[0].pflx, steps[0].pqw)
+	var stwls []map[int][]Well
+	if len(steps) > 1 || len(steps[0].pss) > 0 || len(steps[0].psy) > 0 {
+		fmt.Printf("  transient: %d time steps\n", len(steps))
+		for _, s := range steps {
+			if err := d.AddTimeStep(s.kper, s.kstp, s.totim, s.pflx, s.pqw, heads(s)); err != nil {
+				return Domain{}, fmt.Errorf("ReadMODFLOW: %w", err)
+			}
+			if err := d.SetStorage(s.pss, s.psy); err != nil {
+				return Domain{}, fmt.Errorf("ReadMODFLOW: %w", err)
+			}
+			d.steps[len(d.steps)-1].shrd = s.pshr
+			stwls = append(stwls, s.pwel)
+		}
+	}
+	d.setWells(steps[0].pwel, stwls) // internal sources/sinks, at their coordinates when given (WEL/MAW), otherwise prism centroids
+	d.shrd = steps[0].pshr           // shared faces of the steady-state, or first time step, flow field
+	d.SetTransform(xf)               // prisms are held in model coordinates
+	return d, nil
 }
 
-func readGRB(fp string) (map[int]*Prism, map[int][]int, map[int]jaxr) {
+func readGRB(fp string) (map[int]*Prism, map[int][]int, map[int]jaxr, Transform, error) {
 	buf := mmio.OpenBinary(fp)
 	var btyp, bver [50]byte
 	if err := binary.Read(buf, binary.LittleEndian, &btyp); err != nil {
-		log.Fatalln("Fatal error: readGRB read 001 failed: ", err)
+		return nil, nil, nil, Transform{}, fmt.Errorf("readGRB read 001 failed: %w", err)
 	}
 	if err := binary.Read(buf, binary.LittleEndian, &bver); err != nil {
-		log.Fatalln("Fatal error: readGRB read 002 failed: ", err)
+		return nil, nil, nil, Transform{}, fmt.Errorf("readGRB read 002 failed: %w", err)
 	}
 	ttyp, tver := strings.TrimSpace(string(btyp[:])), strings.TrimSpace(string(bver[:]))
 	if tver != "VERSION 1" {
-		log.Fatalf("Error:\n GRB %s version not supported: '%s'", fp, tver)
+		return nil, nil, nil, Transform{}, fmt.Errorf("GRB %s version not supported: '%s'", fp, tver)
 	}
 
 	switch ttyp {
 	case "GRID DIS":
 		// fmt.Println(ttyp, tver)
-		readGRBheader(buf)
+		if _, err := readGRBheader(buf); err != nil {
+			return nil, nil, nil, Transform{}, err
+		}
 		return readGRBgrid(buf)
+	case "GRID DISV":
+		defs, err := readGRBheader(buf)
+		if err != nil {
+			return nil, nil, nil, Transform{}, err
+		}
+		g, err := readGRBvars(buf, defs)
+		if err != nil {
+			return nil, nil, nil, Transform{}, err
+		}
+		return readGRBV(g)
 	case "GRID DISU":
-		// fmt.Println(ttyp, tver)
-		readGRBheader(buf)
-		return readGRBU(buf)
+		defs, err := readGRBheader(buf)
+		if err != nil {
+			return nil, nil, nil, Transform{}, err
+		}
+		g, err := readGRBvars(buf, defs)
+		if err != nil {
+			return nil, nil, nil, Transform{}, err
+		}
+		return readGRBU(g)
 	default:
-		log.Fatalf("GRB type '%s' currently not supported", ttyp)
-		var p map[int]*Prism
-		var c map[int][]int
-		var jaxr map[int]jaxr
-		return p, c, jaxr
+		return nil, nil, nil, Transform{}, fmt.Errorf("GRB type '%s' currently not supported", ttyp)
 	}
 }
 
-func readGRBheader(b *bytes.Reader) {
-	// read past *.grb header
+// grbDef is a variable definition of the *.grb header, i.e., "VERTICES DOUBLE NDIM 2 2 58"
+type grbDef struct {
+	name, typ string
+	n         int // number of values
+}
+
+// readGRBheader reads the *.grb header, returning the definitions of the variables that follow
+func readGRBheader(b *bytes.Reader) ([]grbDef, error) {
 	var bntxt, blentxt [50]byte
 	if err := binary.Read(b, binary.LittleEndian, &bntxt); err != nil {
-		log.Fatalln("Fatal error: readGRBheader read 001 failed: ", err)
+		return nil, fmt.Errorf("readGRBheader read 001 failed: %w", err)
 	}
 	if err := binary.Read(b, binary.LittleEndian, &blentxt); err != nil {
-		log.Fatalln("Fatal error: readGRBheader read 002 failed: ", err)
+		return nil, fmt.Errorf("readGRBheader read 002 failed: %w", err)
 	}
 	ntxt, err := strconv.Atoi(strings.TrimSpace(string(bntxt[:])[5:]))
 	if err != nil {
-		log.Fatalln("Fatal error: readGRBheader read 003 failed: ", err)
+		return nil, fmt.Errorf("readGRBheader read 003 failed: %w", err)
 	}
 	lentxt, err := strconv.Atoi(strings.TrimSpace(string(blentxt[:])[7:]))
 	if err != nil {
-		log.Fatalln("Fatal error: readGRBheader read 004 failed: ", err)
+		return nil, fmt.Errorf("readGRBheader read 004 failed: %w", err)
 	}
-	for {
+	defs := make([]grbDef, 0, ntxt)
+	for range ntxt {
 		ln := make([]byte, lentxt)
 		if err := binary.Read(b, binary.LittleEndian, ln); err != nil {
-			log.Fatalln("Fatal error: readGRBheader read 005 failed: ", err)
+			return nil, fmt.Errorf("readGRBheader read 005 failed: %w", err)
 		}
-		// fmt.Println(strings.TrimSpace(string(ln[:])))
-		ntxt--
-		if ntxt <= 0 {
-			break
+		// NAME TYPE NDIM n dim1 .. dimn; scalars: NAME TYPE NDIM 0 # value
+		sp := strings.Fields(string(ln))
+		if len(sp) < 4 || sp[2] != "NDIM" {
+			return nil, fmt.Errorf("readGRBheader: unknown definition '%s'", strings.TrimSpace(string(ln)))
+		}
+		ndim, err := strconv.Atoi(sp[3])
+		if err != nil || len(sp) < 4+ndim {
+			return nil, fmt.Errorf("readGRBheader: bad dimensions '%s'", strings.TrimSpace(string(ln)))
+		}
+		def := grbDef{name: sp[0], typ: sp[1], n: 1}
+		for _, v := range sp[4 : 4+ndim] {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				return nil, fmt.Errorf("readGRBheader: bad dimensions '%s'", strings.TrimSpace(string(ln)))
+			}
+			def.n *= n
+		}
+		defs = append(defs, def)
+	}
+	return defs, nil
+}
+
+// grbVars holds the *.grb variables, by name
+type grbVars struct {
+	i map[string][]int32
+	f map[string][]float64
+}
+
+// readGRBvars reads the *.grb variables as defined by the header
+func readGRBvars(b *bytes.Reader, defs []grbDef) (grbVars, error) {
+	g := grbVars{i: make(map[string][]int32), f: make(map[string][]float64)}
+	for _, def := range defs {
+		switch def.typ {
+		case "INTEGER":
+			v := make([]int32, def.n)
+			if err := binary.Read(b, binary.LittleEndian, v); err != nil {
+				return g, fmt.Errorf("readGRBvars %s read failed: %w", def.name, err)
+			}
+			g.i[def.name] = v
+		case "DOUBLE":
+			v := make([]float64, def.n)
+			if err := binary.Read(b, binary.LittleEndian, v); err != nil {
+				return g, fmt.Errorf("readGRBvars %s read failed: %w", def.name, err)
+			}
+			g.f[def.name] = v
+		default:
+			return g, fmt.Errorf("readGRBvars %s: type %s not supported", def.name, def.typ)
+		}
+	}
+	if !mmio.ReachedEOF(b) {
+		return g, fmt.Errorf("readGRBvars: have not reached EOF")
+	}
+	return g, nil
+}
+
+// ints returns the integer variables of the given names, or an error if any are missing
+func (g grbVars) ints(nams ...string) ([][]int32, error) {
+	v := make([][]int32, len(nams))
+	for k, n := range nams {
+		var ok bool
+		if v[k], ok = g.i[n]; !ok {
+			return nil, fmt.Errorf("grb variable %s not found", n)
 		}
 	}
+	return v, nil
 }
 
-func readGRBgrid(buf *bytes.Reader) (map[int]*Prism, map[int][]int, map[int]jaxr) {
+// floats returns the double-precision variables of the given names, or an error if any are missing
+func (g grbVars) floats(nams ...string) ([][]float64, error) {
+	v := make([][]float64, len(nams))
+	for k, n := range nams {
+		var ok bool
+		if v[k], ok = g.f[n]; !ok {
+			return nil, fmt.Errorf("grb variable %s not found", n)
+		}
+	}
+	return v, nil
+}
+
+// transform returns the georeference of the grid (XORIGIN, YORIGIN and, when saved, ANGROT)
+func (g grbVars) transform() (Transform, error) {
+	o, err := g.floats("XORIGIN", "YORIGIN")
+	if err != nil {
+		return Transform{}, err
+	}
+	xf := Transform{X0: o[0][0], Y0: o[1][0]}
+	if a, ok := g.f["ANGROT"]; ok && len(a) > 0 {
+		xf.Rot = a[0]
+	}
+	return xf, nil
+}
+
+func readGRBgrid(buf *bytes.Reader) (map[int]*Prism, map[int][]int, map[int]jaxr, Transform, error) {
 	g := grbGridHreader{}
-	g.read(buf)
+	if err := g.read(buf); err != nil {
+		return nil, nil, nil, Transform{}, err
+	}
 
-	delr, delc := make(map[int]float64), make(map[int]float64)
+	delr, delc, ytop := make(map[int]float64), make(map[int]float64), 0.
 	for j := 0; j < int(g.NCOL); j++ {
 		delr[j] = mmio.ReadFloat64(buf) // cell width
 	}
 	for i := 0; i < int(g.NROW); i++ {
 		delc[i] = mmio.ReadFloat64(buf) // cell height
-		g.YORIGIN += delc[i]            // adjusting origin from lower-left to upper-left
+		ytop += delc[i]                 // model coordinates of the upper-left, origin at lower-left
 	}
 
 	top, botm := make([]float64, int(g.NROW*g.NCOL)), make([]float64, int(g.NCELLS))
@@ -148,13 +310,13 @@ func readGRBgrid(buf *bytes.Reader) (map[int]*Prism, map[int][]int, map[int]jaxr
 	}
 
 	if !mmio.ReachedEOF(buf) {
-		log.Fatalln("Fatal error: readGRB read 003 failed: have not reached EOF")
+		return nil, nil, nil, Transform{}, fmt.Errorf("readGRB read 003 failed: have not reached EOF")
 	}
 
-	// fmt.Printf("  nl,nr,nc: %v,%v,%v; UL-origin: (%v, %v)\n", g.NLAY, g.NROW, g.NCOL, g.XORIGIN, g.YORIGIN)
+	// fmt.Printf("  nl,nr,nc: %v,%v,%v; origin: (%v, %v) rotated %v\n", g.NLAY, g.NROW, g.NCOL, g.XORIGIN, g.YORIGIN, g.ANGROT)
 	c, cpl, prsms := 0, int(g.NROW*g.NCOL), make(map[int]*Prism)
 	for k := 0; k < int(g.NLAY); k++ {
-		cl, o := 0, complex(g.XORIGIN, g.YORIGIN) // converted to upper-left (above)
+		cl, o := 0, complex(0., ytop) // upper-left
 		for i := 0; i < int(g.NROW); i++ {
 			dy := -delc[i]
 			for j := 0; j < int(g.NCOL); j++ {
@@ -165,21 +327,9 @@ func readGRBgrid(buf *bytes.Reader) (map[int]*Prism, map[int][]int, map[int]jaxr
 				z := []complex128{o + complex(0., dy), o, o + complex(dx, 0.), o + complex(dx, dy)}
 				if idomain[c] >= 0 {
 					var p Prism
-					if k == 0 {
-						p.New(z, top[c], botm[c], top[cl], 0., defaultPorosity)
-					} else {
-						for kk := k - 1; kk >= 0; kk-- {
-							c0 := kk*cpl + cl
-							if idomain[c0] > 0 {
-								p.New(z, botm[c0], botm[c], top[cl], 0., defaultPorosity)
-								break
-							} else if idomain[c0] == 0 {
-								p.New(z, botm[c0], botm[c], botm[c0], 0., defaultPorosity)
-								break
-							} else if kk == 0 {
-								p.New(z, top[cl], botm[c], top[cl], 0., defaultPorosity)
-							}
-						}
+					t, bn := layerTop(k, cl, cpl, top, botm, idomain)
+					if err := p.New(z, t, botm[c], bn, 0., defaultPorosity); err != nil {
+						return nil, nil, nil, Transform{}, fmt.Errorf("readGRB cell %d: %w", c, err)
 					}
 					prsms[c] = &p
 					// fmt.Println(c, k, i, j, p.Z, p.Top, p.Bot)
@@ -188,7 +338,7 @@ func readGRBgrid(buf *bytes.Reader) (map[int]*Prism, map[int][]int, map[int]jaxr
 				c++
 				cl++
 			}
-			o = complex(g.XORIGIN, imag(o)+dy)
+			o = complex(0., imag(o)+dy)
 		}
 	}
 
@@ -217,14 +367,14 @@ func readGRBgrid(buf *bytes.Reader) (map[int]*Prism, map[int][]int, map[int]jaxr
 			}
 		}
 		if c1[0] != i {
-			log.Fatalf("Fatal error: readGRB cell id check 004 failed:\nCreated: %v\nFound: %v\n", i, c1[0])
+			return nil, nil, nil, Transform{}, fmt.Errorf("readGRB cell id check 004 failed: created: %v; found: %v", i, c1[0])
 		}
 		if len(c1)-1 != len(connkey) {
-			log.Fatalf("Fatal error: readGRB connectivity check 005 failed, cell %d\n:\nCreated: %v\nFound: %v\n", i, conn[i], c1[1:])
+			return nil, nil, nil, Transform{}, fmt.Errorf("readGRB connectivity check 005 failed, cell %d: created: %v; found: %v", i, conn[i], c1[1:])
 		}
 		for _, c := range c1[1:] {
 			if !connkey[c] {
-				log.Fatalf("Fatal error: readGRB connectivity check 006 failed, cell %d\n:\nCreated: %v\nFound: %v\n", i, conn[i], c1[1:])
+				return nil, nil, nil, Transform{}, fmt.Errorf("readGRB connectivity check 006 failed, cell %d: created: %v; found: %v", i, conn[i], c1[1:])
 			}
 		}
 
@@ -245,7 +395,7 @@ func readGRBgrid(buf *bytes.Reader) (map[int]*Prism, map[int][]int, map[int]jaxr
 	}
 
 	if len(jaxrOut) != int(g.NJA-g.NCELLS) {
-		log.Fatalf("Fatal error: readGRB connectivity check 007 failed, number of connections created (%d) not equal to NJA (less number of cells)\n", len(jaxrOut))
+		return nil, nil, nil, Transform{}, fmt.Errorf("readGRB connectivity check 007 failed, number of connections created (%d) not equal to NJA (less number of cells)", len(jaxrOut))
 	}
 
 	// fmt.Println("left-up-right-down-bottom-top")
@@ -254,151 +404,200 @@ func readGRBgrid(buf *bytes.Reader) (map[int]*Prism, map[int][]int, map[int]jaxr
 	// 	fmt.Println(v)
 	// }
 
-	return prsms, conn, jaxrOut
+	return prsms, conn, jaxrOut, Transform{X0: g.XORIGIN, Y0: g.YORIGIN, Rot: g.ANGROT}, nil
 }
 
-func readGRBU(buf *bytes.Reader) (map[int]*Prism, map[int][]int, map[int]jaxr) {
-	g := grbuHreader{}
-	g.read(buf)
-
-	nc := int(g.NODES)
-	top, botm := make([]float64, nc), make([]float64, nc)
-	if err := binary.Read(buf, binary.LittleEndian, top); err != nil {
-		panic(err)
+// readGRBV builds the prisms of a DISV grid. Cell vertices (VERTICES/IAVERT/JAVERT) are set clockwise, with lateral
+// face j being the polygon edge from vertex j to vertex j+1; connections are mapped to the edge shared with the neighbour,
+// several connections may share an edge (e.g., quadtree refinement, see readGRBU).
+func readGRBV(g grbVars) (map[int]*Prism, map[int][]int, map[int]jaxr, Transform, error) {
+	vi, err := g.ints("NCELLS", "NLAY", "NCPL", "IAVERT", "JAVERT", "IA", "JA", "IDOMAIN")
+	if err != nil {
+		return nil, nil, nil, Transform{}, fmt.Errorf("readGRBV: %w", err)
 	}
-	if err := binary.Read(buf, binary.LittleEndian, botm); err != nil {
-		panic(err)
+	vf, err := g.floats("TOP", "BOTM", "VERTICES")
+	if err != nil {
+		return nil, nil, nil, Transform{}, fmt.Errorf("readGRBV: %w", err)
 	}
-
-	ia, ja, icelltype := make([]int32, nc+1), make([]int32, int(g.NJA)), make([]int32, nc)
-	if err := binary.Read(buf, binary.LittleEndian, ia); err != nil {
-		panic(err)
+	xf, err := g.transform()
+	if err != nil {
+		return nil, nil, nil, Transform{}, fmt.Errorf("readGRBV: %w", err)
 	}
-	if err := binary.Read(buf, binary.LittleEndian, ja); err != nil {
-		panic(err)
+	ncells, nlay, cpl := int(vi[0][0]), int(vi[1][0]), int(vi[2][0])
+	iavert, javert, ia, ja := vi[3], vi[4], vi[5], vi[6]
+	top, botm, verts := vf[0], vf[1], vf[2]
+	if ncells != nlay*cpl || len(iavert) != cpl+1 || len(ia) != ncells+1 || len(top) != cpl || len(botm) != ncells {
+		return nil, nil, nil, Transform{}, fmt.Errorf("readGRBV: inconsistent dimensions")
 	}
-	if err := binary.Read(buf, binary.LittleEndian, icelltype); err != nil {
-		panic(err)
+	idomain := make([]int, ncells)
+	for i, v := range vi[7] {
+		idomain[i] = int(v)
 	}
 
-	type ddd struct {
-		NVERT, NJAVERT int32
-	}
-	var dddd ddd
-	if err := binary.Read(buf, binary.LittleEndian, &dddd); err != nil {
-		panic(err)
+	// cell vertices, clockwise and without closing vertex
+	zs := make([][]complex128, cpl)
+	for c := range cpl {
+		if _, zs[c], err = grbPolygon(iavert, javert, verts, c); err != nil {
+			return nil, nil, nil, Transform{}, fmt.Errorf("readGRBV: %w", err)
+		}
 	}
-	fmt.Println(dddd)
-
-	// if !mmio.ReachedEOF(buf) {
-	// 	log.Fatalln("Fatal error: readGRB read 003 failed: have not reached EOF")
-	// }
 
-	// // fmt.Printf("  nl,nr,nc: %v,%v,%v; UL-origin: (%v, %v)\n", g.NLAY, g.NROW, g.NCOL, g.XORIGIN, g.YORIGIN)
-	// c, cpl, prsms := 0, nc, make(map[int]*Prism)
-	// for k := 0; k < int(g.NLAY); k++ {
-	// 	cl, o := 0, complex(g.XORIGIN, g.YORIGIN) // converted to upper-left (above)
-	// 	for i := 0; i < int(g.NROW); i++ {
-	// 		dy := -delc[i]
-	// 		for j := 0; j < int(g.NCOL); j++ {
-	// 			dx := delr[j]
-	// 			// p1---p2   y       0---nc
-	// 			//  | c |    |       |       clockwise, left-top-right-bottom
-	// 			// p0---p3   0---x   nr
-	// 			z := []complex128{o + complex(0., dy), o, o + complex(dx, 0.), o + complex(dx, dy)}
-	// 			if idomain[c] >= 0 {
-	// 				var p Prism
-	// 				if k == 0 {
-	// 					p.New(z, top[c], botm[c], top[cl], 0., defaultPorosity)
-	// 				} else {
-	// 					for kk := k - 1; kk >= 0; kk-- {
-	// 						c0 := kk*cpl + cl
-	// 						if idomain[c0] > 0 {
-	// 							p.New(z, botm[c0], botm[c], top[cl], 0., defaultPorosity)
-	// 							break
-	// 						} else if idomain[c0] == 0 {
-	// 							p.New(z, botm[c0], botm[c], botm[c0], 0., defaultPorosity)
-	// 							break
-	// 						} else if kk == 0 {
-	// 							p.New(z, top[cl], botm[c], top[cl], 0., defaultPorosity)
-	// 						}
-	// 					}
-	// 				}
-	// 				prsms[c] = &p
-	// 				// fmt.Println(c, k, i, j, p.Z, p.Top, p.Bot)
-	// 			}
-	// 			o += complex(dx, 0.)
-	// 			c++
-	// 			cl++
-	// 		}
-	// 		o = complex(g.XORIGIN, imag(o)+dy)
-	// 	}
-	// }
+	prsms := make(map[int]*Prism)
+	for i := range ncells {
+		if idomain[i] <= 0 {
+			continue
+		}
+		k, c := i/cpl, i%cpl
+		var p Prism
+		t, bn := layerTop(k, c, cpl, top, botm, idomain)
+		if err := p.New(zs[c], t, botm[i], bn, 0., defaultPorosity); err != nil {
+			return nil, nil, nil, Transform{}, fmt.Errorf("readGRBV cell %d: %w", i+1, err)
+		}
+		prsms[i] = &p
+	}
 
-	// conn := g.buildTopology() // make(map[int][]int)
-	// // for i := 0; i < int(g.NCELLS); i++ {
-	// // 	c1 := make([]int, ia[i+1]-ia[i])
-	// // 	for j := ia[i]; j < ia[i+1]; j++ {
-	// // 		c1[j-ia[i]] = ja[j]
-	// // 	}
-	// // 	conn[i] = c1
-	// // }
-
-	// // check connections
-	// jaxrOut, jaxrcnt := make(map[int]jaxr), 0
-	// for i := 0; i < int(g.NCELLS); i++ {
-	// 	i1, c1 := make([]int, ia[i+1]-ia[i]), make([]int, ia[i+1]-ia[i]) // MF6 order (looks to be) above-up-left-right-down-below
-	// 	for j := ia[i]; j < ia[i+1]; j++ {
-	// 		c1[j-ia[i]] = ja[j]
-	// 		i1[j-ia[i]] = j
-	// 	}
+	conn, jaxrOut := make(map[int][]int, len(prsms)), make(map[int]jaxr)
+	for i := range ncells {
+		if _, ok := prsms[i]; !ok {
+			continue
+		}
+		k, nf := i/cpl, len(prsms[i].Z)
+		c1 := make([]int, nf+2) // [laterals]-bottom-top, holding the first neighbour of shared faces
+		for j := range c1 {
+			c1[j] = -1
+		}
+		if int(ja[ia[i]-1])-1 != i {
+			return nil, nil, nil, Transform{}, fmt.Errorf("readGRBV cell id check failed: created: %d; found: %d", i+1, ja[ia[i]-1])
+		}
+		for jj := int(ia[i]); jj < int(ia[i+1])-1; jj++ { // skipping the diagonal
+			m := int(ja[jj]) - 1
+			if _, ok := prsms[m]; !ok {
+				return nil, nil, nil, Transform{}, fmt.Errorf("readGRBV cell %d connected to unknown cell %d", i+1, m+1)
+			}
+			pos := nf // bottom
+			switch km := m / cpl; {
+			case km < k:
+				pos = nf + 1 // top
+			case km == k:
+				if pos = faceOf(prsms[i], prsms[m]); pos < 0 || pos >= nf {
+					return nil, nil, nil, Transform{}, fmt.Errorf("readGRBV cell %d: no edge shared with connected cell %d", i+1, m+1)
+				}
+			}
+			if c1[pos] < 0 {
+				c1[pos] = m
+			}
+			jaxrOut[len(jaxrOut)] = jaxr{f: i, t: m, p: pos, i: jj}
+		}
+		conn[i] = c1
+	}
+	return prsms, conn, jaxrOut, xf, nil
+}
 
-	// 	connkey := make(map[int]bool) // temporary map for list checking
-	// 	for _, v := range conn[i] {
-	// 		if v >= 0 {
-	// 			connkey[v] = true
-	// 		}
-	// 	}
-	// 	if c1[0] != i {
-	// 		log.Fatalf("Fatal error: readGRB cell id check 004 failed:\nCreated: %v\nFound: %v\n", i, c1[0])
-	// 	}
-	// 	if len(c1)-1 != len(connkey) {
-	// 		log.Fatalf("Fatal error: readGRB connectivity check 005 failed, cell %d\n:\nCreated: %v\nFound: %v\n", i, conn[i], c1[1:])
-	// 	}
-	// 	for _, c := range c1[1:] {
-	// 		if !connkey[c] {
-	// 			log.Fatalf("Fatal error: readGRB connectivity check 006 failed, cell %d\n:\nCreated: %v\nFound: %v\n", i, conn[i], c1[1:])
-	// 		}
-	// 	}
+// readGRBU builds the prisms of a DISU grid, cell vertices (VERTICES/IAVERT/JAVERT) are required. Connections are
+// mapped to the prism face (polygon edge) they share, several connections may share a face (e.g., quadtree refinement).
+func readGRBU(g grbVars) (map[int]*Prism, map[int][]int, map[int]jaxr, Transform, error) {
+	vi, err := g.ints("NODES", "IA", "JA")
+	if err != nil {
+		return nil, nil, nil, Transform{}, fmt.Errorf("readGRBU: %w", err)
+	}
+	vf, err := g.floats("TOP", "BOT")
+	if err != nil {
+		return nil, nil, nil, Transform{}, fmt.Errorf("readGRBU: %w", err)
+	}
+	xf, err := g.transform()
+	if err != nil {
+		return nil, nil, nil, Transform{}, fmt.Errorf("readGRBU: %w", err)
+	}
+	vv, err := g.ints("IAVERT", "JAVERT")
+	if err != nil {
+		return nil, nil, nil, Transform{}, fmt.Errorf("readGRBU: DISU grid saved without vertices: %w", err)
+	}
+	verts, ok := g.f["VERTICES"]
+	if !ok {
+		return nil, nil, nil, Transform{}, fmt.Errorf("readGRBU: DISU grid saved without vertices")
+	}
+	nc, ia, ja := int(vi[0][0]), vi[1], vi[2]
+	top, botm := vf[0], vf[1]
+	if len(ia) != nc+1 || len(vv[0]) != nc+1 || len(top) != nc || len(botm) != nc {
+		return nil, nil, nil, Transform{}, fmt.Errorf("readGRBU: inconsistent dimensions")
+	}
 
-	// 	// fmt.Println(conn[i], c1[1:])
-	// 	for j, v := range conn[i] {
-	// 		for j2, v2 := range c1[1:] {
-	// 			if v == v2 {
-	// 				jaxrOut[jaxrcnt] = jaxr{
-	// 					f: c1[0],
-	// 					t: v,
-	// 					p: j,
-	// 					i: i1[j2+1],
-	// 				} // JA to prsm.conn cross-reference
-	// 				jaxrcnt++
-	// 			}
-	// 		}
-	// 	}
-	// }
+	prsms := make(map[int]*Prism, nc)
+	for i := range nc {
+		_, z, err := grbPolygon(vv[0], vv[1], verts, i)
+		if err != nil {
+			return nil, nil, nil, Transform{}, fmt.Errorf("readGRBU: %w", err)
+		}
+		var p Prism
+		if err := p.New(z, top[i], botm[i], top[i], 0., defaultPorosity); err != nil {
+			return nil, nil, nil, Transform{}, fmt.Errorf("readGRBU cell %d: %w", i+1, err)
+		}
+		prsms[i] = &p
+	}
 
-	// if len(jaxrOut) != int(g.NJA-g.NCELLS) {
-	// 	log.Fatalf("Fatal error: readGRB connectivity check 007 failed, number of connections created (%d) not equal to NJA (less number of cells)\n", len(jaxrOut))
-	// }
+	conn, jaxrOut := make(map[int][]int, nc), make(map[int]jaxr)
+	for i := range nc {
+		if int(ja[ia[i]-1])-1 != i {
+			return nil, nil, nil, Transform{}, fmt.Errorf("readGRBU cell id check failed: created: %d; found: %d", i+1, ja[ia[i]-1])
+		}
+		c1 := make([]int, len(prsms[i].Z)+2) // [laterals]-bottom-top, holding the first neighbour of shared faces
+		for j := range c1 {
+			c1[j] = -1
+		}
+		for jj := int(ia[i]); jj < int(ia[i+1])-1; jj++ { // skipping the diagonal
+			m := int(ja[jj]) - 1
+			if _, ok := prsms[m]; !ok {
+				return nil, nil, nil, Transform{}, fmt.Errorf("readGRBU cell %d connected to unknown cell %d", i+1, m+1)
+			}
+			pos := faceOf(prsms[i], prsms[m])
+			if pos < 0 {
+				return nil, nil, nil, Transform{}, fmt.Errorf("readGRBU cell %d: no face shared with connected cell %d", i+1, m+1)
+			}
+			if c1[pos] < 0 {
+				c1[pos] = m
+			}
+			jaxrOut[len(jaxrOut)] = jaxr{f: i, t: m, p: pos, i: jj}
+		}
+		conn[i] = c1
+	}
+	return prsms, conn, jaxrOut, xf, nil
+}
 
-	// // fmt.Println("left-up-right-down-bottom-top")
-	// // fmt.Println("\nJAXR [from to pos ia]:")
-	// // for _, v := range jaxrOut {
-	// // 	fmt.Println(v)
-	// // }
+// grbPolygon returns the vertex IDs (0-based) and (model) coordinates of cell (or cell2d) c, clockwise and without closing vertex
+func grbPolygon(iavert, javert []int32, verts []float64, c int) ([]int, []complex128, error) {
+	iv := make([]int, 0, iavert[c+1]-iavert[c])
+	for _, v := range javert[iavert[c]-1 : iavert[c+1]-1] {
+		iv = append(iv, int(v)-1)
+	}
+	if n := len(iv); n > 1 && iv[0] == iv[n-1] {
+		iv = iv[:n-1]
+	}
+	z := make([]complex128, len(iv))
+	for j, v := range iv {
+		if v < 0 || 2*v+1 >= len(verts) {
+			return nil, nil, fmt.Errorf("cell %d: unknown vertex %d", c+1, v+1)
+		}
+		z[j] = complex(verts[2*v], verts[2*v+1])
+	}
+	z, rev := clockwise(z)
+	if rev {
+		slices.Reverse(iv)
+	}
+	return iv, z, nil
+}
 
-	// return prsms, conn, jaxrOut
-	return nil, nil, nil
+// layerTop returns the top and initial saturated thickness of the cell in layer k (0-based) of layered grid cell cl,
+// the top being the bottom of the first cell above that is not a vertical pass-through (IDOMAIN<0)
+func layerTop(k, cl, cpl int, top, botm []float64, idomain []int) (float64, float64) {
+	for kk := k - 1; kk >= 0; kk-- {
+		c0 := kk*cpl + cl
+		if idomain[c0] > 0 {
+			return botm[c0], top[cl]
+		} else if idomain[c0] == 0 {
+			return botm[c0], botm[c0]
+		}
+	}
+	return top[cl], top[cl]
 }
 
 func (g *grbGridHreader) buildTopology() map[int][]int {
@@ -457,142 +656,202 @@ type grbGridHreader struct {
 	XORIGIN, YORIGIN, ANGROT      float64
 }
 
-func (g *grbGridHreader) read(b *bytes.Reader) bool {
-	err := binary.Read(b, binary.LittleEndian, g)
-	// fmt.Println(*g)
-	if err != nil {
-		if err == io.EOF {
-			return true
-		}
-		log.Fatalln("Fatal error: grbGridHread failed: ", err)
+func (g *grbGridHreader) read(b *bytes.Reader) error {
+	if err := binary.Read(b, binary.LittleEndian, g); err != nil {
+		return fmt.Errorf("grbGridHread failed: %w", err)
 	}
-	return false
+	// fmt.Println(*g)
+	return nil
 }
 
-type grbuHreader struct {
-	NODES, NJA               int32
-	XORIGIN, YORIGIN, ANGROT float64
+// cbcStep holds the prism fluxes of a single MODFLOW time step
+type cbcStep struct {
+	kper, kstp int
+	totim      float64
+	pflx       map[int][]float64
+	pqw        map[int]float64
+	pss, psy   map[int]float64                 // storage fluxes (STO-SS, STO-SY), positive released from storage
+	pwel       map[int][]Well                  // internal sources/sinks, NaN coordinates when not given as auxiliary variables
+	pshr       map[int]map[int][]faceNeighbour // faces shared with several prisms (DISV/DISU)
 }
 
-func (g *grbuHreader) read(b *bytes.Reader) bool {
-	err := binary.Read(b, binary.LittleEndian, g)
-	// fmt.Println(*g)
-	if err != nil {
-		if err == io.EOF {
-			return true
-		}
-		log.Fatalln("Fatal error: grbGridHread failed: ", err)
-	}
-	return false
+// cbcRecord is a single entry of a budget list (IMETH=6)
+type cbcRecord struct {
+	node int
+	q    float64
+	aux  map[string]float64
+	pkg  string // package name
 }
 
-func readCBC(fp string, jaxr map[int]jaxr) (pflx map[int][]float64, pqw map[int]float64) {
+// readCBC reads the cell-by-cell budget file, returning prism fluxes for every time step saved, in time order
+func readCBC(fp string, conn map[int][]int, jaxr map[int]jaxr) ([]cbcStep, error) {
 	bflx := mmio.OpenBinary(fp)
-	dat1D := make(map[string]map[int]float64)
-	dat2D := make(map[string]map[int]map[int]float64)
+	var steps []cbcStep
+	var dat1D map[string]map[int]float64
+	var dat2D map[string]map[int]map[int]float64
+	var dat2L map[string][]cbcRecord
+	cur := cbcStep{kper: -1}
+	flush := func() error {
+		if cur.kper < 0 {
+			return nil
+		}
+		if len(steps) == 0 { // print available outputs
+			fmt.Println("  CBC: 2D")
+			for i := range dat2D {
+				fmt.Printf("      %s\n", i)
+			}
+			fmt.Println("  CBC: 1D")
+			for i := range dat1D {
+				fmt.Printf("      %s\n", i)
+			}
+		}
+		pflx, pqw, err := cbcToFlux(dat1D, dat2D, conn, jaxr)
+		if err != nil {
+			return fmt.Errorf("KPER %d KSTP %d: %w", cur.kper, cur.kstp, err)
+		}
+		if len(pflx) == 0 {
+			return nil // FLOW-JA-FACE not saved for this time step
+		}
+		cur.pflx, cur.pqw, cur.pwel, cur.pshr = pflx, pqw, cbcToSinks(dat2L), cbcToShared(dat1D["FLOW-JA-FACE"], jaxr)
+		cur.pss, cur.psy = cbcStorage(dat1D["STO-SS"]), cbcStorage(dat1D["STO-SY"])
+		steps = append(steps, cur)
+		return nil
+	}
 
 	for {
 		h := cbcHreader{}
-		if h.cbcHread(bflx) {
-			break // EOF
+		if eof, err := h.cbcHread(bflx); err != nil {
+			return nil, err
+		} else if eof {
+			break
+		}
+		if int(h.KPER) != cur.kper || int(h.KSTP) != cur.kstp {
+			if err := flush(); err != nil {
+				return nil, err
+			}
+			cur = cbcStep{kper: int(h.KPER), kstp: int(h.KSTP), totim: h.TOTIM}
+			dat1D = make(map[string]map[int]float64)
+			dat2D = make(map[string]map[int]map[int]float64)
+			dat2L = make(map[string][]cbcRecord)
 		}
 
 		txt := strings.TrimSpace(string(h.TEXT[:]))
 		// fmt.Printf("KSTP %d; KPER %d: %s\n", h.KPER, h.KSTP, txt)
 		switch h.IMETH {
 		case 1: // Read 1D array of size NDIM1*NDIM2*NDIM3
-			m1, n := make(map[int]float64), int(-h.NDIM1*h.NDIM2*h.NDIM3)
-			for i := 0; i < n; i++ {
-				m1[i] = mmio.ReadFloat64(bflx)
+			a1 := make([]float64, int(-h.NDIM1*h.NDIM2*h.NDIM3))
+			if err := binary.Read(bflx, binary.LittleEndian, a1); err != nil {
+				return nil, fmt.Errorf("readCBC %s read failed: %w", txt, err)
+			}
+			m1 := make(map[int]float64, len(a1))
+			for i, v := range a1 {
+				m1[i] = v
 			}
 			dat1D[txt] = m1
 		case 6: // Read text identifiers, auxiliary text labels, and list of information.
 			a := cbcAuxReader{}
-			a.cbcAuxRead(bflx)
+			if err := a.cbcAuxRead(bflx); err != nil {
+				return nil, fmt.Errorf("readCBC %s: %w", txt, err)
+			}
 			auxtext := make([]string, int(a.NDAT))
 			for i := 0; i < int(a.NDAT)-1; i++ {
 				var b1 [16]byte
 				if err := binary.Read(bflx, binary.LittleEndian, &b1); err != nil {
-					log.Fatalln("Fatal error: AUXTEXT read failed: ", err)
+					return nil, fmt.Errorf("readCBC %s AUXTEXT read failed: %w", txt, err)
 				}
-				auxtext[i] = string(b1[:])
+				auxtext[i] = strings.ToUpper(strings.TrimSpace(string(b1[:])))
 			}
+			pkg := strings.TrimSpace(string(a.TXT2ID2[:]))
 			var nlist int32
 			if err := binary.Read(bflx, binary.LittleEndian, &nlist); err != nil {
-				log.Fatalln("Fatal error: NLIST read failed: ", err)
+				return nil, fmt.Errorf("readCBC %s NLIST read failed: %w", txt, err)
 			}
-			d2D := make(map[int]map[int]float64)
+			d2D, lst := make(map[int]map[int]float64), make([]cbcRecord, 0, int(nlist))
 			for i := 0; i < int(nlist); i++ {
 				var id1, id2 int32
 				if err := binary.Read(bflx, binary.LittleEndian, &id1); err != nil {
-					log.Fatalln("Fatal error: ID1 read failed: ", err)
+					return nil, fmt.Errorf("readCBC %s ID1 read failed: %w", txt, err)
 				}
 				if err := binary.Read(bflx, binary.LittleEndian, &id2); err != nil {
-					log.Fatalln("Fatal error: ID2 read failed: ", err)
+					return nil, fmt.Errorf("readCBC %s ID2 read failed: %w", txt, err)
 				}
 				m1 := make(map[int]float64)
 				for j := 0; j < int(a.NDAT); j++ {
 					m1[j] = mmio.ReadFloat64(bflx)
 				}
-				d2D[int(id1)-1] = m1
+				rec := cbcRecord{node: int(id1) - 1, q: m1[0], aux: make(map[string]float64, int(a.NDAT)-1), pkg: pkg}
+				for j := 1; j < int(a.NDAT); j++ {
+					rec.aux[auxtext[j-1]] = m1[j]
+				}
+				lst = append(lst, rec)
+				if m0, ok := d2D[rec.node]; ok { // multiple entries per cell
+					m0[0] += m1[0]
+					continue
+				}
+				d2D[rec.node] = m1
+			}
+			if strings.HasPrefix(txt, "STO-") { // storage saved as a list, collected as an array
+				if dat1D[txt] == nil {
+					dat1D[txt] = make(map[int]float64)
+				}
+				for _, r := range lst {
+					dat1D[txt][r.node] += r.q
+				}
+				continue
+			}
+			if d0, ok := dat2D[txt]; ok { // several packages of the same type
+				for i, m1 := range d2D {
+					if m0, ok := d0[i]; ok {
+						m0[0] += m1[0]
+						continue
+					}
+					d0[i] = m1
+				}
+			} else {
+				dat2D[txt] = d2D
 			}
-			dat2D[txt] = d2D
+			dat2L[txt] = append(dat2L[txt], lst...)
 		default:
-			log.Fatalf("MODFLOW CBC read error: IMETH=%d not supported", h.IMETH)
+			return nil, fmt.Errorf("MODFLOW CBC read error: IMETH=%d not supported (%s)", h.IMETH, txt)
 		}
 	}
-
-	// print available outputs
-	fmt.Println("  CBC: 2D")
-	for i := range dat2D {
-		fmt.Printf("      %s\n", i)
+	if err := flush(); err != nil {
+		return nil, err
 	}
-	fmt.Println("  CBC: 1D")
-	for i := range dat1D {
-		fmt.Printf("      %s\n", i)
+	if len(steps) == 0 {
+		return nil, fmt.Errorf("MODFLOW CBC read error: FLOW-JA-FACE not found in %s", fp)
 	}
+	return steps, nil
+}
 
+// cbcToFlux converts the budget records of a single time step to prism fluxes, ordered as the prism connectivity conn
+func cbcToFlux(dat1D map[string]map[int]float64, dat2D map[string]map[int]map[int]float64, conn map[int][]int, jaxr map[int]jaxr) (pflx map[int][]float64, pqw map[int]float64, err error) {
 	pflx = make(map[int][]float64)
 	if val, ok := dat1D["FLOW-JA-FACE"]; ok {
 		// fmt.Printf("\nFLOW-JA-FACE data (%d):\n", len(val))
 		for _, ja := range jaxr { // initialize
-			pflx[ja.f] = []float64{0., 0., 0., 0., 0., 0.} // left-up-right-down-bottom-top
+			pflx[ja.f] = make([]float64, len(conn[ja.f])) // [laterals]-bottom-top; left-up-right-down-bottom-top (DIS)
 		}
 		for _, ja := range jaxr {
 			// fmt.Printf("from %d to %d flux %v\n", ja.f, ja.t, val[ja.i])
-			pflx[ja.f][ja.p] = val[ja.i]
+			pflx[ja.f][ja.p] += val[ja.i] // summed over neighbours sharing a face
 		}
 	}
 
 	pqw = make(map[int]float64)
-	if val, ok := dat2D["WEL"]; ok {
-		// fmt.Println("\nWEL data:")
-		for i, v := range val {
-			if len(v) > 1 {
-				log.Fatalln("MODFLOW CBC read error: WEL given with greater than 1 NDAT")
-			}
-			// fmt.Println(i, v[0])
-			pqw[i] += v[0]
-		}
-	}
-	if val, ok := dat2D["RCH"]; ok {
-		// fmt.Println("\nWEL data:")
-		for i, v := range val {
-			if len(v) > 1 {
-				log.Fatalln("MODFLOW CBC read error: RCH given with greater than 1 NDAT")
+	for txt, val := range dat2D {
+		switch cbcBudgetType(txt) {
+		case cbcAreal:
+			for i, v := range val {
+				if _, ok := pflx[i]; !ok {
+					return nil, nil, fmt.Errorf("MODFLOW CBC read error: %s given to unknown cell %d", txt, i+1)
+				}
+				pflx[i][len(pflx[i])-1] += v[0] // top; auxiliary variables follow
 			}
-			// fmt.Println(i, v[0])
-			pflx[i][5] = v[0]
-		}
-	}
-	if val, ok := dat2D["CHD"]; ok {
-		// fmt.Println("\nCHD data:")
-		for i, v := range val {
-			if len(v) > 1 {
-				log.Fatalln("MODFLOW CBC read error: CHD given with greater than 1 NDAT")
+		case cbcInternal:
+			for i, v := range val {
+				pqw[i] += v[0]
 			}
-			// fmt.Println(i, v[0])
-			pqw[i] += v[0]
 		}
 	}
 
@@ -604,6 +863,93 @@ func readCBC(fp string, jaxr map[int]jaxr) (pflx map[int][]float64, pqw map[int]
 	return
 }
 
+// cbcToShared returns the fluxes of every neighbour of faces shared with several prisms, nil when there are none
+func cbcToShared(val map[int]float64, jaxr map[int]jaxr) map[int]map[int][]faceNeighbour {
+	type face struct{ f, p int }
+	n := make(map[face]int)
+	for _, ja := range jaxr {
+		n[face{ja.f, ja.p}]++
+	}
+	var shrd map[int]map[int][]faceNeighbour
+	for k := range len(jaxr) { // in JA order
+		ja := jaxr[k]
+		if n[face{ja.f, ja.p}] < 2 {
+			continue
+		}
+		if shrd == nil {
+			shrd = make(map[int]map[int][]faceNeighbour)
+		}
+		if shrd[ja.f] == nil {
+			shrd[ja.f] = make(map[int][]faceNeighbour)
+		}
+		shrd[ja.f][ja.p] = append(shrd[ja.f][ja.p], faceNeighbour{pid: ja.t, q: val[ja.i]})
+	}
+	return shrd
+}
+
+// cbcStorage returns the non-zero storage fluxes of a budget array (IMETH=1), by cell. MODFLOW reports flows into the
+// cell as positive, i.e., water released from storage.
+func cbcStorage(val map[int]float64) map[int]float64 {
+	var sto map[int]float64
+	for i, v := range val {
+		if v == 0. {
+			continue
+		}
+		if sto == nil {
+			sto = make(map[int]float64)
+		}
+		sto[i] = v
+	}
+	return sto
+}
+
+// budget record types
+const (
+	cbcSkip     = iota // not a cell flux (i.e., DATA-SPDIS, DATA-SAT, transfers to the mover)
+	cbcAreal           // areal flux applied to the top face (RCH, EVT, UZF)
+	cbcInternal        // internal source/sink (WEL, MAW, CHD, DRN, RIV, GHB, SFR, LAK, etc.)
+)
+
+// cbcBudgetType returns how a budget list record (IMETH=6) of text txt is applied to the prisms
+func cbcBudgetType(txt string) int {
+	switch {
+	case strings.HasPrefix(txt, "DATA-"), strings.HasSuffix(txt, "TO-MVR"):
+		return cbcSkip
+	case txt == "RCH", txt == "RCHA", txt == "EVT", txt == "EVTA", strings.HasPrefix(txt, "UZF-GW"):
+		return cbcAreal
+	default:
+		return cbcInternal
+	}
+}
+
+// cbcToSinks returns the internal sources/sinks of a single time step, named by budget term and package. Coordinates are
+// read from auxiliary variables X and Y (or XCOORD and YCOORD) when saved to the budget, otherwise left NaN (prism centroid).
+// Budget files hold no boundary names (BOUNDNAMES), only numeric auxiliary variables, so boundaries are left unnamed.
+func cbcToSinks(dat2L map[string][]cbcRecord) map[int][]Well {
+	coord := func(aux map[string]float64, nams ...string) float64 {
+		for _, n := range nams {
+			if v, ok := aux[n]; ok {
+				return v
+			}
+		}
+		return math.NaN()
+	}
+	wls := make(map[int][]Well)
+	for _, txt := range slices.Sorted(maps.Keys(dat2L)) {
+		if cbcBudgetType(txt) != cbcInternal {
+			continue
+		}
+		for _, r := range dat2L[txt] {
+			if r.q == 0. {
+				continue
+			}
+			w := Well{X: coord(r.aux, "X", "XCOORD"), Y: coord(r.aux, "Y", "YCOORD"), Z: math.NaN(), Q: r.q, Pkg: txt, Name: r.pkg}
+			wls[r.node] = append(wls[r.node], w)
+		}
+	}
+	return wls
+}
+
 type cbcHreader struct {
 	KSTP, KPER          int32
 	TEXT                [16]byte
@@ -614,15 +960,15 @@ type cbcHreader struct {
 	DELT, PERTIM, TOTIM float64
 }
 
-func (h *cbcHreader) cbcHread(b *bytes.Reader) bool {
+func (h *cbcHreader) cbcHread(b *bytes.Reader) (bool, error) {
 	err := binary.Read(b, binary.LittleEndian, h)
 	if err != nil {
 		if err == io.EOF {
-			return true
+			return true, nil
 		}
-		log.Fatalln("Fatal error: cbcHread failed: ", err)
+		return false, fmt.Errorf("cbcHread failed: %w", err)
 	}
-	return false
+	return false, nil
 }
 
 type cbcAuxReader struct {
@@ -630,19 +976,35 @@ type cbcAuxReader struct {
 	NDAT                               int32
 }
 
-func (a *cbcAuxReader) cbcAuxRead(b *bytes.Reader) {
-	err := binary.Read(b, binary.LittleEndian, a)
-	if err != nil {
-		log.Fatalln("Fatal error: cbcAuxRead failed: ", err)
+func (a *cbcAuxReader) cbcAuxRead(b *bytes.Reader) error {
+	if err := binary.Read(b, binary.LittleEndian, a); err != nil {
+		return fmt.Errorf("cbcAuxRead failed: %w", err)
 	}
+	return nil
+}
+
+// dvStep holds the dependent variables (i.e., HEAD) of a single MODFLOW time step
+type dvStep struct {
+	kper, kstp int
+	totim      float64
+	dv         map[string]map[int]float64
 }
 
-func readDependentVariable(fp string) map[string]map[int]float64 {
-	bflx, m1 := mmio.OpenBinary(fp), make(map[string]map[int]float64)
+// readDependentVariable reads a dependent variable file (e.g., *.hds), returning every time step saved, in time order
+func readDependentVariable(fp string) ([]dvStep, error) {
+	bflx := mmio.OpenBinary(fp)
+	var dvs []dvStep
+	var m1 map[string]map[int]float64
 	for {
 		h := dvarHreader{}
-		if h.dvarHread(bflx) {
-			break // EOF
+		if eof, err := h.dvarHread(bflx); err != nil {
+			return nil, fmt.Errorf("readDependentVariable %s: %w", fp, err)
+		} else if eof {
+			break
+		}
+		if n := len(dvs); n == 0 || dvs[n-1].kper != int(h.KPER) || dvs[n-1].kstp != int(h.KSTP) {
+			m1 = make(map[string]map[int]float64)
+			dvs = append(dvs, dvStep{kper: int(h.KPER), kstp: int(h.KSTP), totim: h.TOTIM, dv: m1})
 		}
 
 		txt := strings.TrimSpace(string(h.TEXT[:]))
@@ -661,7 +1023,7 @@ func readDependentVariable(fp string) map[string]map[int]float64 {
 			m1[txt][i] = v
 		}
 	}
-	return m1
+	return dvs, nil
 
 	// 	Using br As New BinaryReader(New FileStream(_filepath, FileMode.Open), System.Text.Encoding.Default)
 	// 	Dim cnt As Integer = 1
@@ -698,13 +1060,13 @@ type dvarHreader struct {
 	ILAY          int32 // NLAY (DIS); NLAY (DISV);     1 (DISU)
 }
 
-func (h *dvarHreader) dvarHread(b *bytes.Reader) bool {
+func (h *dvarHreader) dvarHread(b *bytes.Reader) (bool, error) {
 	err := binary.Read(b, binary.LittleEndian, h)
 	if err != nil {
 		if err == io.EOF {
-			return true
+			return true, nil
 		}
-		log.Fatalln("Fatal error: dvarHread failed: ", err)
+		return false, fmt.Errorf("dvarHread failed: %w", err)
 	}
-	return false
+	return false, nil
 }
@@ -0,0 +1,379 @@
+package ptrack
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// disvGrid is a single-layer DISV grid fixture: cells are given by their vertex IDs (0-based) and their connected
+// cells (0-based), in the order saved to IA/JA
+type disvGrid struct {
+	verts    [][2]float64
+	cells    [][]int
+	nbrs     [][]int
+	top, bot float64
+}
+
+// quadtreeGrid is a 2x2 cell refined to its right: cell 0 (0..2, 0..2) shares its right face with cells 1 (2..3, 0..1)
+// and 2 (2..3, 1..2), the hanging vertex at (2,1) not being a vertex of cell 0
+func quadtreeGrid() disvGrid {
+	return disvGrid{
+		verts: [][2]float64{{0, 0}, {0, 2}, {2, 2}, {2, 0}, {2, 1}, {3, 2}, {3, 1}, {3, 0}},
+		cells: [][]int{{0, 1, 2, 3}, {3, 4, 6, 7}, {4, 2, 5, 6}}, // clockwise, lateral face j from vertex j to j+1
+		nbrs:  [][]int{{1, 2}, {0, 2}, {0, 1}},
+		top:   1.,
+		bot:   0.,
+	}
+}
+
+// ja returns the JA position (0-based) of the connection from cell c to cell m
+func (g disvGrid) ja(c, m int) int {
+	k := 0
+	for i := range c {
+		k += len(g.nbrs[i]) + 1
+	}
+	for j, n := range g.nbrs[c] {
+		if n == m {
+			return k + j + 1
+		}
+	}
+	return -1
+}
+
+// writeGRB saves the grid as a MODFLOW 6 binary grid file (*.disv.grb), polygons being closed
+func (g disvGrid) writeGRB(t *testing.T, fp string) {
+	t.Helper()
+	nc := len(g.cells)
+	ia, ja, iavert, javert := []int32{1}, []int32{}, []int32{1}, []int32{}
+	for c, iv := range g.cells {
+		ja = append(ja, int32(c+1))
+		for _, m := range g.nbrs[c] {
+			ja = append(ja, int32(m+1))
+		}
+		ia = append(ia, int32(len(ja)+1))
+		for _, v := range iv {
+			javert = append(javert, int32(v+1))
+		}
+		javert = append(javert, int32(iv[0]+1))
+		iavert = append(iavert, int32(len(javert)+1))
+	}
+	verts, top, botm, cx, ones := make([]float64, 0, 2*len(g.verts)), make([]float64, nc), make([]float64, nc), make([]float64, nc), make([]int32, nc)
+	for _, v := range g.verts {
+		verts = append(verts, v[0], v[1])
+	}
+	for c := range nc {
+		top[c], botm[c], ones[c] = g.top, g.bot, 1
+	}
+
+	defs := []string{
+		fmt.Sprintf("NCELLS INTEGER NDIM 0 # %d", nc), "NLAY INTEGER NDIM 0 # 1", fmt.Sprintf("NCPL INTEGER NDIM 0 # %d", nc),
+		fmt.Sprintf("NVERT INTEGER NDIM 0 # %d", len(g.verts)), fmt.Sprintf("NJAVERT INTEGER NDIM 0 # %d", len(javert)),
+		fmt.Sprintf("NJA INTEGER NDIM 0 # %d", len(ja)),
+		"XORIGIN DOUBLE NDIM 0 # 0", "YORIGIN DOUBLE NDIM 0 # 0", "ANGROT DOUBLE NDIM 0 # 0",
+		fmt.Sprintf("TOP DOUBLE NDIM 1 %d", nc), fmt.Sprintf("BOTM DOUBLE NDIM 1 %d", nc),
+		fmt.Sprintf("VERTICES DOUBLE NDIM 2 2 %d", len(g.verts)),
+		fmt.Sprintf("CELLX DOUBLE NDIM 1 %d", nc), fmt.Sprintf("CELLY DOUBLE NDIM 1 %d", nc),
+		fmt.Sprintf("IAVERT INTEGER NDIM 1 %d", len(iavert)), fmt.Sprintf("JAVERT INTEGER NDIM 1 %d", len(javert)),
+		fmt.Sprintf("IA INTEGER NDIM 1 %d", len(ia)), fmt.Sprintf("JA INTEGER NDIM 1 %d", len(ja)),
+		fmt.Sprintf("IDOMAIN INTEGER NDIM 1 %d", nc), fmt.Sprintf("ICELLTYPE INTEGER NDIM 1 %d", nc),
+	}
+	var b bytes.Buffer
+	pad := func(s string, n int) { fmt.Fprintf(&b, "%-*s", n, s) }
+	pad("GRID DISV", 50)
+	pad("VERSION 1", 50)
+	pad(fmt.Sprintf("NTXT %d", len(defs)), 50)
+	pad("LENTXT 100", 50)
+	for _, d := range defs {
+		pad(d, 100)
+	}
+	for _, v := range []any{
+		[]int32{int32(nc), 1, int32(nc), int32(len(g.verts)), int32(len(javert)), int32(len(ja))},
+		[]float64{0, 0, 0}, top, botm, verts, cx, cx, iavert, javert, ia, ja, ones, ones,
+	} {
+		binary.Write(&b, binary.LittleEndian, v)
+	}
+	if err := os.WriteFile(fp, b.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// cbcArray is a budget array (IMETH=1), e.g., FLOW-JA-FACE in JA order or storage by cell
+type cbcArray struct {
+	txt string
+	v   []float64
+}
+
+// cbcList is a budget list (IMETH=6), nodes are 1-based and every value holds the flux followed by its auxiliary variables
+type cbcList struct {
+	txt, pkg string
+	aux      []string
+	nodes    []int32
+	vals     [][]float64
+}
+
+// cbcFixtureStep is a single time step of a cell-by-cell budget file
+type cbcFixtureStep struct {
+	kper, kstp int32
+	totim      float64
+	arrays     []cbcArray
+	lists      []cbcList
+}
+
+// writeCBC saves the time steps as a MODFLOW 6 cell-by-cell budget file
+func writeCBC(t *testing.T, fp string, steps []cbcFixtureStep) {
+	t.Helper()
+	var b bytes.Buffer
+	w := func(v any) { binary.Write(&b, binary.LittleEndian, v) }
+	s16 := func(s string) { fmt.Fprintf(&b, "%-16s", s) }
+	for _, s := range steps {
+		for _, a := range s.arrays {
+			w([]int32{s.kstp, s.kper})
+			s16(a.txt)
+			w([]int32{int32(len(a.v)), 1, -1, 1})
+			w([]float64{1, 1, s.totim})
+			w(a.v)
+		}
+		for _, l := range s.lists {
+			w([]int32{s.kstp, s.kper})
+			s16(l.txt)
+			w([]int32{1, 1, -1, 6})
+			w([]float64{1, 1, s.totim})
+			for _, id := range []string{"GWF", "GWF", "GWF", l.pkg} {
+				s16(id)
+			}
+			w(int32(len(l.aux) + 1))
+			for _, a := range l.aux {
+				s16(a)
+			}
+			w(int32(len(l.nodes)))
+			for k, n := range l.nodes {
+				w([]int32{n, n})
+				w(l.vals[k])
+			}
+		}
+	}
+	if err := os.WriteFile(fp, b.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReadGRBVSharedFace(t *testing.T) {
+	g := quadtreeGrid()
+	fp := filepath.Join(t.TempDir(), "m.disv.grb")
+	g.writeGRB(t, fp)
+	_, conn, jx, _, err := readGRB(fp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if conn[0][2] != 1 || conn[1][0] != 0 || conn[2][0] != 0 || conn[1][1] != 2 || conn[2][3] != 1 {
+		t.Errorf("connectivity %v, want cell 0 right face holding cell 1, cells 1 and 2 connected left to 0 and to one another", conn)
+	}
+	for _, ja := range jx {
+		if ja.f == 0 && ja.p != 2 {
+			t.Errorf("cell 0 connected to cell %d through face %d, want the right face (2)", ja.t, ja.p)
+		}
+	}
+}
+
+func TestReadMODFLOWSharedFacePerStep(t *testing.T) {
+	g := quadtreeGrid()
+	dir := t.TempDir()
+	g.writeGRB(t, filepath.Join(dir, "m.disv.grb"))
+	fja := func(q1, q2 float64) []float64 { // cell 0 discharging q1 to cell 1 and q2 to cell 2
+		v := make([]float64, 9)
+		v[g.ja(0, 1)], v[g.ja(1, 0)] = -q1, q1
+		v[g.ja(0, 2)], v[g.ja(2, 0)] = -q2, q2
+		return v
+	}
+	writeCBC(t, filepath.Join(dir, "m.cbc"), []cbcFixtureStep{
+		{kper: 1, kstp: 1, totim: 1., arrays: []cbcArray{{"FLOW-JA-FACE", fja(.6, .4)}}},
+		{kper: 2, kstp: 1, totim: 2., arrays: []cbcArray{{"FLOW-JA-FACE", fja(.3, .7)}}},
+	})
+	d, err := ReadMODFLOW(filepath.Join(dir, "m"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(d.steps) != 2 {
+		t.Fatalf("%d time steps read, want 2", len(d.steps))
+	}
+	for k, want := range [][2]float64{{-.6, -.4}, {-.3, -.7}} {
+		s := d.steps[k].shrd[0][2]
+		if len(s) != 2 || s[0].pid != 1 || s[1].pid != 2 || s[0].q != want[0] || s[1].q != want[1] {
+			t.Errorf("time step %d: cell 0 right face shared with %v, want cells 1 and 2 discharging %v", k, s, want)
+		}
+		if f := d.steps[k].flx[0][2]; math.Abs(f+1.) > 1e-12 {
+			t.Errorf("time step %d: cell 0 right face flux %g, want -1", k, f)
+		}
+		fg, err := d.FlowGraphAt(k)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if q := fg.dn[0][2]; q != -want[1] {
+			t.Errorf("time step %d: flow graph routes %g from cell 0 to 2, want %g", k, q, -want[1])
+		}
+	}
+}
+
+func TestReadGRBVTwoCells(t *testing.T) {
+	g := disvGrid{
+		verts: [][2]float64{{0, 0}, {0, 1}, {1, 1}, {1, 0}, {2, 1}, {2, 0}},
+		cells: [][]int{{0, 1, 2, 3}, {3, 5, 4, 2}}, // cell 1 saved counter-clockwise
+		nbrs:  [][]int{{1}, {0}},
+		top:   1.,
+		bot:   0.,
+	}
+	fp := filepath.Join(t.TempDir(), "m.disv.grb")
+	g.writeGRB(t, fp)
+	prsms, conn, jx, _, err := readGRB(fp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []complex128{1 + 1i, 2 + 1i, 2, 1}; !slices.Equal(prsms[1].Z, want) {
+		t.Errorf("cell 1 vertices %v, want clockwise %v", prsms[1].Z, want)
+	}
+	if !slices.Equal(conn[0], []int{-1, -1, 1, -1, -1, -1}) || !slices.Equal(conn[1], []int{-1, -1, -1, 0, -1, -1}) {
+		t.Errorf("connectivity %v, want cell 0 right face (2) to cell 1, and cell 1 left face (3, reversed) to cell 0", conn)
+	}
+	if len(jx) != 2 || jx[0] != (jaxr{f: 0, t: 1, p: 2, i: 1}) || jx[1] != (jaxr{f: 1, t: 0, p: 3, i: 3}) {
+		t.Errorf("JA cross-reference %v", jx)
+	}
+}
+
+func TestGRBPolygon(t *testing.T) {
+	verts := []float64{0, 0, 1, 0, 1, 1, 0, 1}
+	iavert, javert := []int32{1, 6}, []int32{1, 2, 3, 4, 1} // counter-clockwise, closed
+	iv, z, err := grbPolygon(iavert, javert, verts, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(iv, []int{3, 2, 1, 0}) || !slices.Equal(z, []complex128{1i, 1 + 1i, 1, 0}) {
+		t.Errorf("got vertices %v at %v, want clockwise and without closing vertex", iv, z)
+	}
+	if _, _, err := grbPolygon([]int32{1, 3}, []int32{1, 9}, verts, 0); err == nil {
+		t.Error("expected an error for an unknown vertex")
+	}
+}
+
+func TestCBCBudgetType(t *testing.T) {
+	for txt, want := range map[string]int{
+		"DATA-SPDIS": cbcSkip, "DATA-SAT": cbcSkip, "WEL-TO-MVR": cbcSkip,
+		"RCH": cbcAreal, "RCHA": cbcAreal, "EVT": cbcAreal, "EVTA": cbcAreal, "UZF-GWRCH": cbcAreal, "UZF-GWET": cbcAreal,
+		"WEL": cbcInternal, "MAW": cbcInternal, "CHD": cbcInternal, "DRN": cbcInternal, "RIV": cbcInternal,
+		"GHB": cbcInternal, "SFR": cbcInternal, "LAK": cbcInternal,
+	} {
+		if got := cbcBudgetType(txt); got != want {
+			t.Errorf("%s: budget type %d, want %d", txt, got, want)
+		}
+	}
+}
+
+func TestReadMODFLOWStorageClosesBalance(t *testing.T) {
+	g := disvGrid{
+		verts: [][2]float64{{0, 0}, {0, 1}, {1, 1}, {1, 0}, {2, 1}, {2, 0}},
+		cells: [][]int{{0, 1, 2, 3}, {3, 2, 4, 5}},
+		nbrs:  [][]int{{1}, {0}},
+		top:   1.,
+		bot:   0.,
+	}
+	read := func(t *testing.T, sto []cbcArray) (Domain, error) {
+		dir := t.TempDir()
+		g.writeGRB(t, filepath.Join(dir, "m.disv.grb"))
+		writeCBC(t, filepath.Join(dir, "m.cbc"), []cbcFixtureStep{{ // river leakage to cell 1 discharges to cell 0, taken into storage
+			kper: 1, kstp: 1, totim: 1.,
+			arrays: append([]cbcArray{{"FLOW-JA-FACE", []float64{0, 1, 0, -1}}}, sto...),
+			lists:  []cbcList{{txt: "RIV", pkg: "RIV-1", nodes: []int32{2}, vals: [][]float64{{1}}}},
+		}})
+		d, err := ReadMODFLOW(filepath.Join(dir, "m"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := d.SetWaterlooOptions(WaterlooOptions{M: 20, N: 5, Workers: 1, Progress: func(int, int) {}}); err != nil {
+			t.Fatal(err)
+		}
+		return d, d.MakeWaterloo(&EulerSpace{Ds: .01})
+	}
+
+	if _, err := read(t, nil); err == nil || !strings.Contains(err.Error(), "mass-balance") {
+		t.Fatalf("expected a mass-balance error without storage, got %v", err)
+	}
+	for _, txt := range []string{"STO-SS", "STO-SY"} {
+		d, err := read(t, []cbcArray{{txt, []float64{-1, 0}}})
+		if err != nil {
+			t.Errorf("%s: %v", txt, err)
+			continue
+		}
+		s := d.steps[0]
+		switch txt {
+		case "STO-SS":
+			if s.prsms[0].Qss != -1. {
+				t.Errorf("%s: prism 0 storage %g, want -1", txt, s.prsms[0].Qss)
+			}
+		case "STO-SY":
+			if f := s.flx[0]; f[len(f)-1] != -1. {
+				t.Errorf("%s: prism 0 top flux %g, want -1 leaving across the rising water table", txt, f[len(f)-1])
+			}
+		}
+	}
+}
+
+func TestCBCToSinksNames(t *testing.T) {
+	wls := cbcToSinks(map[string][]cbcRecord{
+		"RIV": {
+			{node: 0, q: -1., aux: map[string]float64{"BOUNDNAME": 7, "COND": 10}, pkg: "RIV-1"},
+			{node: 1, q: -2., aux: map[string]float64{}, pkg: "RIVER2"},
+		},
+		"DATA-SPDIS": {{node: 0, q: 0., aux: map[string]float64{"QX": 1}, pkg: "NPF"}},
+	})
+	if len(wls) != 2 {
+		t.Fatalf("sinks %v, want the 2 river reaches", wls)
+	}
+	if w := wls[0][0]; w.Pkg != "RIV" || w.Name != "RIV-1" || w.Bound != "" {
+		t.Errorf("got %+v, want budget term RIV, package RIV-1 and no boundary name (numeric auxiliary variables are not names)", w)
+	}
+	if w := wls[1][0]; w.Pkg != "RIV" || w.Name != "RIVER2" || w.Bound != "" {
+		t.Errorf("got %+v, want budget term RIV, package RIVER2 and no boundary name", w)
+	}
+}
+
+func TestReadMODFLOWErrors(t *testing.T) {
+	dir := t.TempDir()
+	fprfx := filepath.Join(dir, "m")
+	if _, err := ReadMODFLOW(fprfx); err == nil {
+		t.Error("expected an error without a grid file")
+	}
+
+	quadtreeGrid().writeGRB(t, fprfx+".disv.grb")
+	if _, err := ReadMODFLOW(fprfx); err == nil {
+		t.Error("expected an error without a budget file")
+	}
+
+	writeCBC(t, fprfx+".cbc", []cbcFixtureStep{{kper: 1, kstp: 1, totim: 1., arrays: []cbcArray{{"FLOW-JA-FACE", make([]float64, 9)}}}})
+	b, err := os.ReadFile(fprfx + ".cbc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(fprfx+".cbc", b[:len(b)-20], 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReadMODFLOW(fprfx); err == nil {
+		t.Error("expected an error reading a truncated budget file")
+	}
+
+	b, err = os.ReadFile(fprfx + ".disv.grb")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(fprfx+".disv.grb", b[:len(b)/2], 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReadMODFLOW(fprfx); err == nil {
+		t.Error("expected an error reading a truncated grid file")
+	}
+}
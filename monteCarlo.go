@@ -0,0 +1,285 @@
+package ptrack
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"runtime"
+	"slices"
+	"sort"
+	"sync"
+)
+
+// Distribution samples a random variate
+type Distribution interface {
+	Sample(r *rand.Rand) float64
+}
+
+// Uniform distribution over [Lo, Hi)
+type Uniform struct{ Lo, Hi float64 }
+
+func (u Uniform) Sample(r *rand.Rand) float64 { return u.Lo + r.Float64()*(u.Hi-u.Lo) }
+
+// Normal distribution
+type Normal struct{ Mu, Sigma float64 }
+
+func (n Normal) Sample(r *rand.Rand) float64 { return n.Mu + r.NormFloat64()*n.Sigma }
+
+// LogNormal distribution, Mu and Sigma are those of the variate's natural logarithm
+type LogNormal struct{ Mu, Sigma float64 }
+
+func (l LogNormal) Sample(r *rand.Rand) float64 { return math.Exp(l.Mu + r.NormFloat64()*l.Sigma) }
+
+// MonteCarlo sets up an ensemble of perturbed domain realizations
+type MonteCarlo struct {
+	N           int                  // number of realizations
+	Seed        uint64               // random seed, realizations are given their own stream such that results are reproducible
+	Por         Distribution         // (optional) per-prism porosity, sampled independently for every prism
+	PorZoneMult map[int]Distribution // (optional) porosity multiplier, sampled once per zone (see SetZones)
+	FluxMult    Distribution         // (optional) multiplier applied to all fluxes, sampled once per realization
+	Build       func(*Domain) error  // builds the velocity field of a realization, e.g., (*Domain).MakeVector
+	Percentiles []float64            // travel-time percentiles reported, in [0,1]; defaults to 5th, 50th and 95th
+}
+
+// MonteCarloResult summarizes the fate of a particle set tracked through every realization
+type MonteCarloResult struct {
+	N           int                  // number of realizations
+	EndProb     map[int]float64      // probability of a particle endpoint being located in each prism
+	Percentiles []float64            // travel-time percentiles reported
+	TravelTime  [][]float64          // travel-time percentiles per particle [particle][percentile]
+	Status      []map[TermStatus]int // count of realizations by termination status, per particle
+	Ends        [][]Particle         // particle endpoints [realization][particle], in the domain's coordinate system
+}
+
+// MonteCarlo tracks particles p through mc.N perturbed realizations of the domain using nwrkrs workers,
+// each realization being tracked by a single worker. Stop criteria opt apply to every realization.
+func (d *Domain) MonteCarlo(mc MonteCarlo, p Particles, opt TrackOptions, nwrkrs int) (*MonteCarloResult, error) {
+	if mc.N <= 0 {
+		return nil, fmt.Errorf("MonteCarlo: number of realizations must be positive")
+	}
+	if mc.Build == nil {
+		return nil, fmt.Errorf("MonteCarlo: velocity field builder not given")
+	}
+	p = slices.Clone(p) // converted to model coordinates
+	pids := make([]int, len(p))
+	for k := range p {
+		pid, err := d.findStartingPrism(&p[k])
+		if err != nil {
+			return nil, fmt.Errorf("MonteCarlo: particle %d: %w", k, err)
+		}
+		pids[k] = pid
+	}
+	if nwrkrs <= 0 {
+		nwrkrs = runtime.GOMAXPROCS(0)
+	}
+
+	ends, terms, errs := make([][]Particle, mc.N), make([][]Termination, mc.N), make([]error, mc.N)
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for range min(nwrkrs, mc.N) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for r := range jobs {
+				dr, err := d.realization(&mc, r)
+				if err != nil {
+					errs[r] = fmt.Errorf("realization %d: %w", r, err)
+					continue
+				}
+				ps := make([]*Particle, len(p))
+				for k := range p {
+					pp := p[k]
+					ps[k] = &pp
+				}
+				pl, _, term := dr.trackBatch(ps, pids, opt, 1, false)
+				ends[r], terms[r] = make([]Particle, len(pl)), term
+				for k, a := range pl {
+					ends[r][k] = a[len(a)-1]
+					ends[r][k].T -= a[0].T // travel time
+				}
+			}
+		}()
+	}
+	for r := range mc.N {
+		jobs <- r
+	}
+	close(jobs)
+	wg.Wait()
+	if err := errors.Join(errs...); err != nil {
+		return nil, fmt.Errorf("MonteCarlo: %w", err)
+	}
+
+	// summarize
+	pct := mc.Percentiles
+	if len(pct) == 0 {
+		pct = []float64{.05, .5, .95}
+	}
+	res := MonteCarloResult{
+		N:           mc.N,
+		EndProb:     make(map[int]float64),
+		Percentiles: pct,
+		TravelTime:  make([][]float64, len(p)),
+		Status:      make([]map[TermStatus]int, len(p)),
+		Ends:        ends,
+	}
+	f := 1. / float64(mc.N*len(p))
+	for r := range mc.N {
+		for _, e := range ends[r] {
+			res.EndProb[e.C] += f
+		}
+	}
+	tt := make([]float64, mc.N)
+	for k := range p {
+		res.Status[k] = make(map[TermStatus]int)
+		for r := range mc.N {
+			tt[r] = ends[r][k].T
+			res.Status[k][terms[r][k].Status]++
+		}
+		sort.Float64s(tt)
+		res.TravelTime[k] = make([]float64, len(pct))
+		for j, q := range pct {
+			res.TravelTime[k][j] = percentile(tt, q)
+		}
+	}
+	return &res, nil
+}
+
+// percentile returns the q-th quantile of sorted s, linearly interpolated
+func percentile(s []float64, q float64) float64 {
+	if len(s) == 0 {
+		return math.NaN()
+	}
+	x := q * float64(len(s)-1)
+	i := int(math.Floor(x))
+	if i >= len(s)-1 {
+		return s[len(s)-1]
+	}
+	if i < 0 {
+		return s[0]
+	}
+	return s[i] + (x-float64(i))*(s[i+1]-s[i])
+}
+
+// realization returns a copy of the domain with porosities and fluxes perturbed according to mc, and its velocity field built
+func (d *Domain) realization(mc *MonteCarlo, r int) (*Domain, error) {
+	rng := rand.New(rand.NewPCG(mc.Seed, uint64(r)))
+
+	fm := 1.
+	if mc.FluxMult != nil {
+		fm = mc.FluxMult.Sample(rng)
+	}
+	zm := make(map[int]float64, len(mc.PorZoneMult))
+	zns := make([]int, 0, len(mc.PorZoneMult))
+	for z := range mc.PorZoneMult {
+		zns = append(zns, z)
+	}
+	sort.Ints(zns) // sampling order must be repeatable
+	for _, z := range zns {
+		zm[z] = mc.PorZoneMult[z].Sample(rng)
+	}
+	pids := make([]int, 0, len(d.prsms))
+	for i := range d.prsms {
+		pids = append(pids, i)
+	}
+	sort.Ints(pids)
+	por := make(map[int]float64, len(d.prsms))
+	for _, i := range pids {
+		n := d.prsms[i].Por
+		if mc.Por != nil {
+			n = mc.Por.Sample(rng)
+		}
+		if m, ok := zm[d.zone[i]]; ok {
+			n *= m
+		}
+		if n <= 0. || n > 1. {
+			return nil, fmt.Errorf("prism %d sampled porosity %g out of range (0,1]", i, n)
+		}
+		por[i] = n
+	}
+
+	copyPrisms := func(prsms map[int]*Prism) map[int]*Prism {
+		o := make(map[int]*Prism, len(prsms))
+		for i, q := range prsms {
+			c := *q
+			c.Por = por[i]
+			c.Qss *= fm
+			o[i] = &c
+		}
+		return o
+	}
+	scale := func(flx map[int][]float64, qw map[int]float64, zw map[int][]Well) (map[int][]float64, map[int]float64, map[int][]Well) {
+		if fm == 1. {
+			return flx, qw, zw
+		}
+		fo, qo, wo := make(map[int][]float64, len(flx)), make(map[int]float64, len(qw)), make(map[int][]Well, len(zw))
+		for i, f := range flx {
+			fo[i] = make([]float64, len(f))
+			for j, v := range f {
+				fo[i][j] = v * fm
+			}
+		}
+		for i, v := range qw {
+			qo[i] = v * fm
+		}
+		for i, ws := range zw {
+			wo[i] = make([]Well, len(ws))
+			for j, w := range ws {
+				w.Q *= fm
+				wo[i][j] = w
+			}
+		}
+		return fo, qo, wo
+	}
+	scaleShared := func(shrd map[int]map[int][]faceNeighbour) map[int]map[int][]faceNeighbour {
+		if fm == 1. || shrd == nil {
+			return shrd
+		}
+		o := make(map[int]map[int][]faceNeighbour, len(shrd))
+		for i, fs := range shrd {
+			o[i] = make(map[int][]faceNeighbour, len(fs))
+			for j, ns := range fs {
+				o[i][j] = make([]faceNeighbour, len(ns))
+				for k, n := range ns {
+					n.q *= fm
+					o[i][j][k] = n
+				}
+			}
+		}
+		return o
+	}
+
+	dr := *d
+	dr.VF = nil
+	dr.isrev = false // velocity fields are built forward, then reversed
+	dr.prsms = copyPrisms(d.prsms)
+	dr.flx, dr.qw, dr.zw = scale(d.flx, d.qw, d.zw)
+	dr.shrd = scaleShared(d.shrd)
+	if len(d.steps) > 0 {
+		te := d.steps[len(d.steps)-1].T1
+		dr.steps = make([]*TimeStep, len(d.steps))
+		for k, s := range d.steps {
+			c := *s
+			c.VF = nil
+			c.prsms = copyPrisms(s.prsms)
+			c.flx, c.qw, c.zw = scale(s.flx, s.qw, s.zw)
+			c.shrd = scaleShared(s.shrd)
+			if d.isrev {
+				c.reverse(te) // restores forward saturated thickness
+			}
+			dr.steps[k] = &c
+		}
+	}
+	if d.rw != nil {
+		rw := *d.rw
+		rw.Seed = rng.Uint64()
+		dr.SetRandomWalk(&rw)
+	}
+	if err := mc.Build(&dr); err != nil {
+		return nil, err
+	}
+	if d.isrev {
+		dr.ReverseVectorField()
+	}
+	return &dr, nil
+}
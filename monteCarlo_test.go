@@ -0,0 +1,48 @@
+package ptrack
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMonteCarloSeedReproducible(t *testing.T) {
+	d := rowDomain([]float64{1., 1., 1., 1., 1.}, nil)
+	ps := Particles{{I: 1, X: .5, Y: .5, Z: .5}, {I: 2, X: 1.5, Y: .2, Z: .5}}
+	run := func(seed uint64, nwrkrs int) *MonteCarloResult {
+		mc := MonteCarlo{N: 8, Seed: seed, Por: Uniform{.1, .4}, FluxMult: LogNormal{0., .2}, Build: (*Domain).MakeVector}
+		res, err := d.MonteCarlo(mc, ps, TrackOptions{}, nwrkrs)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return res
+	}
+	a := run(42, 1)
+	if b := run(42, 3); !reflect.DeepEqual(a, b) {
+		t.Error("the same seed gave different ensembles")
+	}
+	if c := run(43, 1); reflect.DeepEqual(a.TravelTime, c.TravelTime) {
+		t.Error("different seeds gave the same travel times")
+	}
+}
+
+func TestRealizationScalesSharedFaces(t *testing.T) {
+	d := rowDomain([]float64{1., 1., 1., 1.}, nil)
+	d.shrd = map[int]map[int][]faceNeighbour{0: {2: {{1, -.6}, {2, -.4}}}}
+	if err := d.AddTimeStep(1, 1, 1., d.flx, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	d.steps[0].shrd = d.shrd
+	mc := MonteCarlo{N: 1, FluxMult: Uniform{2., 2.}, Build: func(*Domain) error { return nil }}
+	dr, err := d.realization(&mc, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, shrd := range []map[int]map[int][]faceNeighbour{dr.shrd, dr.steps[0].shrd} {
+		if s := shrd[0][2]; s[0].q != -1.2 || s[1].q != -.8 {
+			t.Errorf("shared face fluxes %v, want doubled", s)
+		}
+	}
+	if s := d.shrd[0][2]; s[0].q != -.6 {
+		t.Errorf("domain shared face fluxes %v altered", s)
+	}
+}
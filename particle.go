@@ -3,7 +3,6 @@ package ptrack
 import (
 	"encoding/json"
 	"fmt"
-	"log"
 	"math"
 	"os"
 
@@ -18,6 +17,8 @@ type Particles []Particle
 type Particle struct {
 	I, C       int
 	X, Y, Z, T float64
+	Zone       int     // zone of prism C
+	Tr, Cr     float64 // retarded travel time and relative concentration (see SetSolute)
 }
 
 // func (p *Particle) State() []float64 {
@@ -41,18 +42,31 @@ func (p *Particle) Dist(p1 *Particle) float64 {
 	return math.Sqrt(math.Pow(p.X-p1.X, 2.) + math.Pow(p.Y-p1.Y, 2.) + math.Pow(p.Z-p1.Z, 2.))
 }
 
-func SaveGeojson(fp string, apl [][]Particle, epl int) {
+// SaveGeojson saves pathlines as polylines, term (optional) are the pathline terminations returned from tracking
+func SaveGeojson(fp string, apl [][]Particle, term []Termination, epl int) error {
 	mmio.DeleteFile("pl.geojson")
 	fc := geojson.NewFeatureCollection()
 
 	// as polylines
-	for _, pln := range apl {
+	for k, pln := range apl {
 		pl := make([][]float64, len(pln))
 		for j, p := range pln {
 			pl[j] = []float64{p.X, p.Y, p.Z}
 		}
 		f := geojson.NewLineStringFeature(pl)
 		f.SetProperty("pid", pln[0].I)
+		if term != nil {
+			f.SetProperty("status", term[k].Status.String())
+			f.SetProperty("termprism", term[k].Prism)
+			f.SetProperty("termface", term[k].Face)
+			if w := term[k].Sink; w != nil && w.Pkg != "" {
+				f.SetProperty("sink", w.Pkg)
+				f.SetProperty("sinkname", w.Name)
+				if w.Bound != "" {
+					f.SetProperty("sinkbound", w.Bound)
+				}
+			}
+		}
 		fc.AddFeature(f)
 	}
 
@@ -71,18 +85,28 @@ func SaveGeojson(fp string, apl [][]Particle, epl int) {
 
 	rawJSON, err := fc.MarshalJSON()
 	if err != nil {
-		log.Fatalf("MarshalJSON error: %v", err)
+		return fmt.Errorf("SaveGeojson MarshalJSON error: %w", err)
 	}
 	mmio.WriteString(fp, string(rawJSON)+"\n")
+	return nil
 }
 
 type pjson struct {
-	X float64 `json:"x"`
-	Y float64 `json:"y"`
-	Z float64 `json:"z"`
-	T float64 `json:"time"`
-	K float64 `json:"k"`
-	I int     `json:"particleid"`
+	X  float64  `json:"x"`
+	Y  float64  `json:"y"`
+	Z  float64  `json:"z"`
+	T  float64  `json:"time"`
+	K  float64  `json:"k"`
+	I  int      `json:"particleid"`
+	Zn int      `json:"zone"`
+	TR *float64 `json:"rtime,omitempty"`
+	CR *float64 `json:"conc,omitempty"`
+	S  string   `json:"status,omitempty"`
+	TP *int     `json:"termprism,omitempty"`
+	TF *int     `json:"termface,omitempty"`
+	SK string   `json:"sink,omitempty"`
+	SN string   `json:"sinkname,omitempty"`
+	SB string   `json:"sinkbound,omitempty"`
 }
 
 // SaveJson saves a format that can be integrated with flopy's cross section plotter:
@@ -90,17 +114,31 @@ type pjson struct {
 // ptlns = pd.read_json('filename.json')
 // ptlns = ptlns.sort_values(["particleid", "time"])
 // xsect.plot_pathline(ptlns, ax=ax, colors='blue', linewidths=1, facecolors='none')
-func SaveJson(fp string, apl [][]Particle) {
+// term (optional) adds the pathline termination to every record, such that endpoints can be filtered by fate
+func SaveJson(fp string, apl [][]Particle, term []Termination) error {
 	var ptlns []pjson
 	for j, pln := range apl {
+		sol := len(pln) > 0 && pln[0].Cr > 0. // solute tracked
 		for _, p := range pln {
 			pt := pjson{
-				X: p.X,
-				Y: p.Y,
-				Z: p.Z,
-				T: p.T,
-				K: 0,
-				I: j, //p.I,
+				X:  p.X,
+				Y:  p.Y,
+				Z:  p.Z,
+				T:  p.T,
+				K:  0,
+				I:  j, //p.I,
+				Zn: p.Zone,
+			}
+			if sol {
+				pt.TR, pt.CR = &p.Tr, &p.Cr
+			}
+			if term != nil {
+				pt.S = term[j].Status.String()
+				pt.TP = &term[j].Prism
+				pt.TF = &term[j].Face
+				if w := term[j].Sink; w != nil {
+					pt.SK, pt.SN, pt.SB = w.Pkg, w.Name, w.Bound
+				}
 			}
 			ptlns = append(ptlns, pt)
 		}
@@ -126,15 +164,15 @@ func SaveJson(fp string, apl [][]Particle) {
 	}
 	f, err := os.Create(fp)
 	if err != nil {
-		panic(err)
+		return fmt.Errorf("SaveJson: %w", err)
 	}
 	defer f.Close()
 
 	encoder := json.NewEncoder(f)
 	encoder.SetIndent("", "")
 
-	err = encoder.Encode(ptlns)
-	if err != nil {
-		panic(err)
+	if err = encoder.Encode(ptlns); err != nil {
+		return fmt.Errorf("SaveJson: %w", err)
 	}
+	return nil
 }
@@ -5,18 +5,48 @@ type ParticleTracker interface {
 	track(done <-chan interface{}, p *Particle, q *Prism, vf VelocityFielder) <-chan Particle
 }
 
-// trackToPrismExit tracks particle to the exit point of a prism
-func trackToPrismExit(p *Particle, q *Prism, w VelocityFielder, pt ParticleTracker) []Particle {
+// trackToPrismExit tracks particle to the exit point of a prism, or until particle time tx is reached
+func trackToPrismExit(p *Particle, q *Prism, w VelocityFielder, pt ParticleTracker, tx float64) []Particle {
 	//panic("to check")
 	// pcoll := [][]float64{p.State()}
 	pcoll := []Particle{*p}
 	done := make(chan interface{})
-	for pstate := range pt.track(done, p, q, w) {
+	ch := pt.track(done, p, q, w)
+	for pstate := range ch {
+		if pstate.T > tx { // interpolate to tx
+			p0 := pcoll[len(pcoll)-1]
+			f := (tx - p0.T) / (pstate.T - p0.T)
+			pstate.X = p0.X + f*(pstate.X-p0.X)
+			pstate.Y = p0.Y + f*(pstate.Y-p0.Y)
+			pstate.Z = p0.Z + f*(pstate.Z-p0.Z)
+			pstate.T = tx
+			pcoll = append(pcoll, pstate)
+			break
+		}
 		pcoll = append(pcoll, pstate)
-		if !q.Contains(p) {
+		if !q.Contains(&pstate) {
 			break // left prism
 		}
+		if wm, ok := w.(*WatMethSoln); ok && wm.nearWell(&pstate) >= 0 {
+			break // captured by well
+		}
 	}
 	close(done)
+	for range ch {
+		// wait for the tracker to stop before resetting the particle state
+	}
+	*p = pcoll[len(pcoll)-1]
 	return pcoll
 }
+
+// copyTracker returns a copy of a particle tracker that holds state (i.e., an adaptive time step),
+// such that it is not shared among concurrent workers
+func copyTracker(pt ParticleTracker) ParticleTracker {
+	switch t := pt.(type) {
+	case *RungeKuttaAdaptive:
+		c := *t
+		return &c
+	default:
+		return pt
+	}
+}
@@ -11,17 +11,19 @@ import (
 type Prism struct {
 	Z                           []complex128
 	Top, Bot, Area, Bn, Por, Tn float64
+	Dbdt                        float64 // rate of change of Bn from time Tn (transient cases)
+	Qss                         float64 // specific storage flux distributed over the prism, positive released from storage (transient cases)
 }
 
 // New prism constructor
-func (q *Prism) New(z []complex128, top, bot, bn, tn, porosity float64) {
+func (q *Prism) New(z []complex128, top, bot, bn, tn, porosity float64) error {
 	q.Z = z // complex coordinates
 	q.Top = top
 	q.Bot = bot
 	q.Por = porosity
 	q.Bn = bn // saturated thickness at time step tn
 	q.Tn = tn // initial time step (both bn and tn will adjust in transient cases)
-	q.computeArea()
+	return q.computeArea()
 }
 
 // Function IsClockwise() As Boolean
@@ -33,8 +35,11 @@ func (q *Prism) New(z []complex128, top, bot, bn, tn, porosity float64) {
 // Return db1 >= 0.0
 // End Function
 
-func (q *Prism) computeArea() {
+func (q *Prism) computeArea() error {
 	nfaces := len(q.Z)
+	if nfaces < 3 {
+		return fmt.Errorf("vertex error, prism given with %d vertices", nfaces)
+	}
 	q.Area = 0.
 	xo, yo := real(q.Z[0]), imag(q.Z[0])
 	for j, z := range q.Z {
@@ -43,11 +48,9 @@ func (q *Prism) computeArea() {
 	}
 	q.Area /= -2. // negative used here because vertices are entered in clockwise order
 	if q.Area <= 0. {
-		for i, z := range q.Z {
-			fmt.Printf("%d,%f,%f\n", i+1, real(z), imag(z))
-		}
-		panic("vertex error, may be given in counter-clockwise order")
+		return fmt.Errorf("vertex error, may be given in counter-clockwise order: %v", q.Z)
 	}
+	return nil
 }
 
 func (q *Prism) Saturation() float64 {
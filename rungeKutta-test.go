@@ -2,23 +2,13 @@ package ptrack
 
 import (
 	"math"
-	"math/cmplx"
 )
 
 // testTracktoExit track particle to the next point
 func (rk *RungeKutta) TestTracktoExit(p *Particle, q *Prism, w VelocityFielder) []Particle {
 	var aout []Particle
 	nearwell := func() bool {
-		wm := w.(*WatMethSoln)
-		if !cmplx.IsNaN(wm.zwl[0]) {
-			for _, zwell := range wm.zwl {
-				zl := (complex(p.X, p.Y) - wm.zc) / wm.r // complex local coordinate
-				if cmplx.Abs(zl-zwell) < wellTol/real(wm.r) {
-					return true
-				}
-			}
-		}
-		return false
+		return w.(*WatMethSoln).nearWell(p) >= 0
 	}
 	for {
 		if !q.Contains(p) || nearwell() {
@@ -34,16 +24,7 @@ func (rk *RungeKutta) TestTracktoExit(p *Particle, q *Prism, w VelocityFielder)
 func (rk *RungeKuttaAdaptive) TestTracktoExit(p *Particle, q *Prism, w VelocityFielder) []Particle {
 	var aout []Particle
 	nearwell := func() bool {
-		wm := w.(*WatMethSoln)
-		if !cmplx.IsNaN(wm.zwl[0]) {
-			for _, zwell := range wm.zwl {
-				zl := (complex(p.X, p.Y) - wm.zc) / wm.r // complex local coordinate
-				if cmplx.Abs(zl-zwell) < wellTol/real(wm.r) {
-					return true
-				}
-			}
-		}
-		return false
+		return w.(*WatMethSoln).nearWell(p) >= 0
 	}
 	for {
 		if !q.Contains(p) || nearwell() {
@@ -0,0 +1,103 @@
+package ptrack
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// binIndex is a uniform bin grid over the XY-extent of the domain, used for point-in-prism lookups.
+// Every bin holds the (sorted) IDs of the prisms whose planform extent overlaps the bin,
+// vertical position is resolved by the prisms themselves.
+type binIndex struct {
+	xn, yn, dx, dy float64
+	nx, ny         int
+	bins           [][]int
+}
+
+// buildIndex builds the spatial index of the domain, sized to about one prism column per bin
+func (d *Domain) buildIndex() {
+	if len(d.prsms) == 0 {
+		d.idx = nil
+		return
+	}
+	_, _, yn, yx, xn, xx := d.getExtent()
+	w, h := math.Max(xx-xn, tol), math.Max(yx-yn, tol)
+	nb := float64(len(d.prsms)) / float64(max(d.Nly, 1))
+	nx := max(1, int(math.Ceil(math.Sqrt(nb*w/h))))
+	ny := max(1, int(math.Ceil(nb/float64(nx))))
+
+	idx := binIndex{xn: xn, yn: yn, dx: w / float64(nx), dy: h / float64(ny), nx: nx, ny: ny}
+	idx.bins = make([][]int, nx*ny)
+	for i, q := range d.prsms {
+		qyn, qyx, qxn, qxx := q.getExtentsXY()
+		i0, j0 := idx.bin(qxn-tol, qyn-tol)
+		i1, j1 := idx.bin(qxx+tol, qyx+tol)
+		for jj := j0; jj <= j1; jj++ {
+			for ii := i0; ii <= i1; ii++ {
+				k := jj*nx + ii
+				idx.bins[k] = append(idx.bins[k], i)
+			}
+		}
+	}
+	for _, b := range idx.bins {
+		sort.Ints(b)
+	}
+	d.idx = &idx
+}
+
+// bin returns the (clamped) bin column and row of coordinate (x,y)
+func (idx *binIndex) bin(x, y float64) (int, int) {
+	i := min(max(int(math.Floor((x-idx.xn)/idx.dx)), 0), idx.nx-1)
+	j := min(max(int(math.Floor((y-idx.yn)/idx.dy)), 0), idx.ny-1)
+	return i, j
+}
+
+// candidates returns the sorted IDs of the prisms whose planform extent may contain (x,y)
+func (idx *binIndex) candidates(x, y float64) []int {
+	if x < idx.xn-tol || y < idx.yn-tol || x > idx.xn+float64(idx.nx)*idx.dx+tol || y > idx.yn+float64(idx.ny)*idx.dy+tol {
+		return nil
+	}
+	i, j := idx.bin(x, y)
+	return idx.bins[j*idx.nx+i]
+}
+
+// locateAll returns the (sorted) IDs of all prisms containing point (x,y,z)
+func (d *Domain) locateAll(x, y, z float64) []int {
+	var pids []int
+	if d.idx == nil { // brute force solution
+		for i, r := range d.prsms {
+			if r.ContainsXYZ(x, y, z) {
+				pids = append(pids, i)
+			}
+		}
+		sort.Ints(pids)
+		return pids
+	}
+	for _, i := range d.idx.candidates(x, y) {
+		if d.prsms[i].ContainsXYZ(x, y, z) {
+			pids = append(pids, i)
+		}
+	}
+	return pids
+}
+
+// Locate returns the ID of the prism containing point (x,y,z), given in the domain's coordinate system (see SetCoordinates).
+// Points on a shared face/edge return the lowest prism ID, such that the result is repeatable.
+func (d *Domain) Locate(x, y, z float64) (int, error) {
+	xm, ym := d.toModel(x, y)
+	pid, err := d.locate(xm, ym, z)
+	if err != nil {
+		return -1, fmt.Errorf("point (x,y,z): %6.3f %6.3f %6.3f not in domain", x, y, z)
+	}
+	return pid, nil
+}
+
+// locate returns the ID of the prism containing point (x,y,z), in model coordinates
+func (d *Domain) locate(x, y, z float64) (int, error) {
+	pids := d.locateAll(x, y, z)
+	if len(pids) == 0 {
+		return -1, fmt.Errorf("point (x,y,z): %6.3f %6.3f %6.3f not in domain", x, y, z)
+	}
+	return pids[0], nil
+}
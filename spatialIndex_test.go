@@ -0,0 +1,49 @@
+package ptrack
+
+import "testing"
+
+func TestLocate(t *testing.T) {
+	d := rowDomain([]float64{1., 1., 1., 1., 1.}, nil)
+	for _, c := range []struct {
+		x, y, z float64
+		want    int
+	}{
+		{.5, .5, .5, 0},
+		{1., .5, .5, 0}, // shared face, lowest prism ID
+		{3., 1., 1., 2},
+		{0., 0., 0., 0}, // domain corner
+		{4., 1., 1., 3},
+	} {
+		if pid, err := d.Locate(c.x, c.y, c.z); err != nil || pid != c.want {
+			t.Errorf("(%g,%g,%g): got prism %d (%v), want %d", c.x, c.y, c.z, pid, err, c.want)
+		}
+	}
+	for _, c := range [][3]float64{{4.5, .5, .5}, {-.1, .5, .5}, {2.5, 1.1, .5}, {2.5, .5, 1.1}, {2.5, .5, -.1}} {
+		if pid, err := d.Locate(c[0], c[1], c[2]); err == nil {
+			t.Errorf("%v: got prism %d, want an error for a point outside the domain", c, pid)
+		}
+	}
+}
+
+func TestIndexSizedByLayers(t *testing.T) {
+	prsms, conn := make(map[int]*Prism), make(map[int][]int)
+	for ly := range 2 {
+		for j := range 3 {
+			var p Prism
+			x0, x1, top := float64(j), float64(j+1), float64(2-ly)
+			if err := p.New([]complex128{complex(x0, 0), complex(x0, 1), complex(x1, 1), complex(x1, 0)}, top, top-1., top, 0., .3); err != nil {
+				t.Fatal(err)
+			}
+			prsms[ly*3+j] = &p
+			conn[ly*3+j] = []int{-1, -1, -1, -1, -1, -1}
+		}
+	}
+	d := Domain{Nly: 2}
+	d.New(prsms, conn, nil, nil)
+	if n := len(d.idx.bins); n != 3 {
+		t.Errorf("%d bins, want one per prism column (3)", n)
+	}
+	if pid, err := d.Locate(2.5, .5, .5); err != nil || pid != 5 {
+		t.Errorf("got prism %d (%v), want the lower layer prism 5", pid, err)
+	}
+}
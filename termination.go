@@ -0,0 +1,87 @@
+package ptrack
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// TermStatus is the reason a particle stopped being tracked
+type TermStatus int
+
+const (
+	TermActive      TermStatus = iota // not terminated
+	TermBoundary                      // entered a strong sink boundary condition (well/constant head) prism
+	TermWell                          // captured by a well, solved internal to the prism (Waterloo method)
+	TermWaterTable                    // exited through the top of the water table
+	TermDomainExit                    // exited the model domain
+	TermCycle                         // tracking aborted, particle is cycling among prisms
+	TermEdge                          // particle at an edge/vertex where the next prism could not be resolved
+	TermMaxTime                       // reached the maximum tracking time (TrackOptions)
+	TermMaxVertices                   // reached the maximum number of pathline vertices (TrackOptions)
+	TermZone                          // entered a terminating zone
+	TermWeakSink                      // stopped at a weak sink, according to the weak sink policy
+)
+
+var termStatusNames = []string{"active", "boundary", "well", "watertable", "domainexit", "cycle", "edge", "maxtime", "maxvertices", "zone", "weaksink"}
+
+func (s TermStatus) String() string {
+	if s < 0 || int(s) >= len(termStatusNames) {
+		return "unknown"
+	}
+	return termStatusNames[s]
+}
+
+// Termination records why and where a particle stopped
+type Termination struct {
+	Status TermStatus
+	Prism  int   // terminating prism ID
+	Face   int   // exit face, indexed as the prism fluxes: [laterals]-bottom-top; -1 when internal to the prism
+	Sink   *Well // (optional) the source/sink capturing the particle (boundary, well and weak sink terminations)
+}
+
+// String describes the termination, i.e., "captured by RIV (RIV-1, boundary 7) at prism 1234"; prism IDs are 0-based
+func (t Termination) String() string {
+	if w := t.Sink; w != nil && w.Pkg != "" {
+		var nams []string
+		if w.Name != "" {
+			nams = append(nams, w.Name)
+		}
+		if w.Bound != "" {
+			nams = append(nams, "boundary "+w.Bound)
+		}
+		if len(nams) > 0 {
+			return fmt.Sprintf("captured by %s (%s) at prism %d", w.Pkg, strings.Join(nams, ", "), t.Prism)
+		}
+		return fmt.Sprintf("captured by %s at prism %d", w.Pkg, t.Prism)
+	}
+	return fmt.Sprintf("%s at prism %d", t.Status, t.Prism)
+}
+
+// exitFace returns the face of the prism the particle has exited through (or is closest to), indexed as [laterals]-bottom-top
+func (q *Prism) exitFace(p *Particle) int {
+	nf := len(q.Z)
+	zt := math.Min(q.Top, q.Bn)
+	if q.Bn <= q.Bot {
+		zt = q.Top
+	}
+	fx, dx := nf+1, p.Z-zt // top
+	if d := q.Bot - p.Z; d > dx {
+		fx, dx = nf, d // bottom
+	}
+	for j := range nf {
+		jj := (j + 1) % nf
+		a, b := q.Z[j], q.Z[jj]
+		ab := b - a
+		l := math.Hypot(real(ab), imag(ab))
+		if l == 0. {
+			continue
+		}
+		// signed distance, outward normal is left of the edge when vertices are given clockwise
+		d := (-imag(ab)*(p.X-real(a)) + real(ab)*(p.Y-imag(a))) / l
+		if d > dx {
+			fx, dx = j, d
+		}
+	}
+	return fx
+}
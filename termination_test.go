@@ -0,0 +1,80 @@
+package ptrack
+
+import "testing"
+
+func TestTerminationString(t *testing.T) {
+	for _, c := range []struct {
+		t    Termination
+		want string
+	}{
+		{Termination{Status: TermDomainExit, Prism: 12}, "domainexit at prism 12"},
+		{Termination{Status: TermBoundary, Prism: 0, Sink: &Well{Pkg: "RIV"}}, "captured by RIV at prism 0"},
+		{Termination{Status: TermBoundary, Prism: 3, Sink: &Well{Pkg: "RIV", Name: "RIV-1"}}, "captured by RIV (RIV-1) at prism 3"},
+		{Termination{Status: TermWeakSink, Prism: 3, Sink: &Well{Pkg: "DRN", Name: "DRN-1", Bound: "7"}}, "captured by DRN (DRN-1, boundary 7) at prism 3"},
+	} {
+		if got := c.t.String(); got != c.want {
+			t.Errorf("got %q, want %q", got, c.want)
+		}
+	}
+}
+
+func TestTerminationStatuses(t *testing.T) {
+	vector := func(qx []float64, qw map[int]float64) func() (*Domain, error) {
+		return func() (*Domain, error) {
+			d := rowDomain(qx, qw)
+			return d, d.MakeVector()
+		}
+	}
+	for _, c := range []struct {
+		name  string
+		build func() (*Domain, error)
+		opt   TrackOptions
+		want  TermStatus
+		prism int
+	}{
+		{"domain exit", vector([]float64{1., 1., 1., 1.}, nil), TrackOptions{}, TermDomainExit, 2},
+		{"strong sink", vector([]float64{1., 1., 1., 0.}, map[int]float64{2: -1.}), TrackOptions{}, TermBoundary, 2},
+		{"weak sink", func() (*Domain, error) {
+			d := rowDomain([]float64{1., 1., 1., .5}, map[int]float64{2: -.5})
+			if err := d.SetWeakSinkPolicy(WeakSinkFraction, .3); err != nil {
+				return nil, err
+			}
+			return d, d.MakeVector()
+		}, TrackOptions{}, TermWeakSink, 2},
+		{"maximum time", vector([]float64{1., 1., 1., 1.}, nil), TrackOptions{MaxTime: .2}, TermMaxTime, 1},            // prisms are crossed in .3
+		{"maximum vertices", vector([]float64{1., 1., 1., 1.}, nil), TrackOptions{MaxVertices: 2}, TermMaxVertices, 1}, // release and entry to prism 1
+		{"zone", func() (*Domain, error) {
+			d := rowDomain([]float64{1., 1., 1., 1.}, nil)
+			d.SetZones(map[int]int{1: 5})
+			d.SetStopZones(5)
+			return d, d.MakeVector()
+		}, TrackOptions{}, TermZone, 1},
+		{"cycle", func() (*Domain, error) {
+			d := rowDomain([]float64{1., 1., 1., 1.}, nil)
+			d.flx[1] = []float64{-1., 0., 1., 0., 0., 0.} // discharging back to prism 0
+			return d, d.MakeVector()
+		}, TrackOptions{}, TermCycle, 1},
+		{"well", func() (*Domain, error) {
+			d := rowDomain([]float64{1., 1., .5, .5}, map[int]float64{1: -.5})
+			if err := d.SetWells([]Well{{X: 1.5, Y: .5, Z: .5, Q: -.5}}); err != nil {
+				return nil, err
+			}
+			if err := d.SetWaterlooOptions(WaterlooOptions{M: 20, N: 5, Workers: 1, Progress: func(int, int) {}}); err != nil {
+				return nil, err
+			}
+			return d, d.MakeWaterloo(&RungeKutta{Dt: .001})
+		}, TrackOptions{}, TermWell, 1},
+	} {
+		d, err := c.build()
+		if err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+		_, _, term, err := d.TrackParticles(Particles{{X: .5, Y: .5, Z: .5}}, c.opt, false)
+		if err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+		if term[0].Status != c.want || term[0].Prism != c.prism {
+			t.Errorf("%s: %s, want %s at prism %d", c.name, term[0], c.want, c.prism)
+		}
+	}
+}
@@ -2,46 +2,71 @@ package ptrack
 
 import (
 	"fmt"
-	"log"
 )
 
-// Track a collection of particles through the domain
-func (d *Domain) TrackParticles(p Particles, prnt bool) ([][]Particle, int) {
-	o, c := make([][]Particle, len(p)), 0
-	for k, pp := range p {
-		pid := d.findStartingPrism(&pp)
-		o[k] = d.trackParticle(&pp, pid, prnt)
-		c += len(o[k])
+// Track a collection of particles through the domain, subject to the stop criteria opt,
+// returning the pathlines, the total number of vertices and how each pathline terminated.
+// Particles are given, and pathlines returned, in the domain's coordinate system (see SetCoordinates).
+func (d *Domain) TrackParticles(p Particles, opt TrackOptions, prnt bool) ([][]Particle, int, []Termination, error) {
+	return d.TrackParticlesConcurrent(p, opt, 1, prnt)
+}
+
+// TrackParticlesConcurrent tracks a collection of particles through the domain using nwrkrs workers, pathlines are returned in input order
+func (d *Domain) TrackParticlesConcurrent(p Particles, opt TrackOptions, nwrkrs int, prnt bool) ([][]Particle, int, []Termination, error) {
+	if len(d.VF) == 0 {
+		return nil, 0, nil, fmt.Errorf("TrackParticles: velocity field has not been built")
+	}
+	ps, pids := make([]*Particle, len(p)), make([]int, len(p))
+	for k := range p {
+		pp := p[k]
+		pid, err := d.findStartingPrism(&pp)
+		if err != nil {
+			return nil, 0, nil, fmt.Errorf("TrackParticles: particle %d: %w", k, err)
+		}
+		ps[k] = &pp
+		pids[k] = pid
 	}
-	return o, c
+	o, c, term := d.trackBatch(ps, pids, opt, nwrkrs, prnt)
+	return o, c, term, nil
 }
 
-func (d *Domain) trackParticle(p *Particle, pid int, prnt bool) []Particle {
+// trackParticle tracks particle p, at position k of the batch, from prism pid
+func (t *tracker) trackParticle(k int, p *Particle, pid int) ([]Particle, Termination) {
 	var pl pathline
-	if prnt {
+	if t.prnt {
 		fmt.Printf("  >>> particle %d start point (x,y,z): %6.3f %6.3f %6.3f released in prism %d\n", p.I, p.X, p.Y, p.Z, pid)
 	}
-	d.trackPrismRecurse(p, &pl, pid, -1, prnt)
+	t.wpt = copyTracker(t.d.pt)
+	t.rw = nil
+	if t.d.rw != nil {
+		t.rw = t.d.rw.forParticle(k)
+		t.rw.nmax = t.vertexLimit()
+	}
+	term := t.trackPrisms(p, &pl, pid)
+	if t.d.sol != nil {
+		t.d.sol.apply(pl)
+	}
 
 	// pl = pl[:len(pl)-1]
 	plast := pl[len(pl)-1]
 
-	if prnt {
-		fmt.Printf("\tparticle exit point  (x,y,z,t): %6.3f %6.3f %6.3f %6.3es\n", plast.X, plast.Y, plast.Z, plast.T)
+	if t.prnt {
+		fmt.Printf("\tparticle exit point  (x,y,z,t): %6.3f %6.3f %6.3f %6.3es (%s)\n", plast.X, plast.Y, plast.Z, plast.T, term.Status)
+		if t.d.sol != nil {
+			fmt.Printf("\tretarded travel time %6.3es, relative concentration %6.3e\n", plast.Tr, plast.Cr)
+		}
 	}
 
-	return pl
+	return pl, term
 }
 
-func (d *Domain) findStartingPrism(p *Particle) int {
-	prismIDs := d.ParticleToPrismIDs(p, -1)
-	switch len(prismIDs) {
-	case 1:
-		return prismIDs[0]
-	case 0:
-		log.Fatalf(" ** ERROR, particle start point (x,y,z): %6.3f %6.3f %6.3f point not in domain\n", p.X, p.Y, p.Z)
-		return -1
-	default:
-		return -1
+// findStartingPrism converts particle p, given in the domain's coordinate system, to model coordinates, returning the prism it starts in
+func (d *Domain) findStartingPrism(p *Particle) (int, error) {
+	x, y := p.X, p.Y
+	p.X, p.Y = d.toModel(x, y)
+	pid, err := d.locate(p.X, p.Y, p.Z) // on a shared face/edge, taking the lowest prism ID
+	if err != nil {
+		return -1, fmt.Errorf("particle start (x,y,z): %6.3f %6.3f %6.3f not in domain", x, y, p.Z)
 	}
+	return pid, nil
 }
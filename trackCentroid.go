@@ -1,12 +1,21 @@
 package ptrack
 
 import (
+	"fmt"
 	"math"
 	"sort"
 )
 
-// Track a collection of particles through the centroid of at least 1 model cell
-func (d *Domain) TrackCentroidalParticles(excl map[int]bool, prnt bool) ([][]Particle, int, []int) {
+// Track a collection of particles through the centroid of at least 1 model cell.
+// Stop criteria opt apply to both the forward and reverse-tracked portions of the pathlines.
+// Returned terminations are those of the forward-tracked portion of the pathlines.
+// With a solute set (see SetSolute), relative concentrations decay along the full pathline from its first vertex.
+func (d *Domain) TrackCentroidalParticles(excl map[int]bool, opt TrackOptions, prnt bool) ([][]Particle, int, []int, []Termination) {
+	return d.TrackCentroidalParticlesConcurrent(excl, opt, 1, prnt)
+}
+
+// TrackCentroidalParticlesConcurrent tracks particles through the centroid of at least 1 model cell using nwrkrs workers
+func (d *Domain) TrackCentroidalParticlesConcurrent(excl map[int]bool, opt TrackOptions, nwrkrs int, prnt bool) ([][]Particle, int, []int, []Termination) {
 
 	chknan := func(a []Particle) ([]Particle, bool) {
 		rm, fxd := []int{}, false
@@ -33,27 +42,37 @@ func (d *Domain) TrackCentroidalParticles(excl map[int]bool, prnt bool) ([][]Par
 		return a, fxd
 	}
 
-	np := d.Nprism() - len(excl)
-	o, pxr, c, k := make([][]Particle, np), make([]int, np), 0, 0
-	for pid, p := range d.prsms {
+	pxr := make([]int, 0, d.Nprism())
+	for pid := range d.prsms {
 		if excl[pid] {
 			continue
 		}
-		a := d.trackParticle(p.CentroidParticle(pid), pid, prnt)
+		pxr = append(pxr, pid)
+	}
+	centroids := func() []*Particle {
+		ps := make([]*Particle, len(pxr))
+		for k, pid := range pxr {
+			ps[k] = d.prsms[pid].CentroidParticle(pid)
+		}
+		return ps
+	}
+
+	o, _, term := d.trackBatch(centroids(), pxr, opt, nwrkrs, prnt)
+	c := 0
+	for k, a := range o {
 		if x, ok := chknan(a); ok {
-			a = x
+			o[k] = x
 		}
-		o[k] = a
-		pxr[k] = pid
 		c += len(o[k])
-		k++
 	}
 
 	// reverse tracks
-	println("  reversing flux field..")
+	if prnt {
+		fmt.Println("  reversing flux field..")
+	}
 	d.ReverseVectorField()
-	for k, pid := range pxr {
-		ar := d.trackParticle(d.prsms[pid].CentroidParticle(pid), pid, prnt)
+	or, _, _ := d.trackBatch(centroids(), pxr, opt, nwrkrs, prnt)
+	for k, ar := range or {
 		if x, ok := chknan(ar); ok {
 			ar = x
 		}
@@ -64,7 +83,11 @@ func (d *Domain) TrackCentroidalParticles(excl map[int]bool, prnt bool) ([][]Par
 		for i := range ar {
 			ar[i].T = -ar[i].T // reverse tracking time
 		}
-		o[k] = append(ar[:len(ar)-1], o[k]...)
+		n := len(ar) - 1
+		o[k] = append(ar[:n], o[k]...)
+		if d.sol != nil {
+			d.sol.applyStitched(o[k], n) // concentrations decay downstream from the first vertex
+		}
 	}
 
 	// func() {
@@ -104,5 +127,5 @@ func (d *Domain) TrackCentroidalParticles(excl map[int]bool, prnt bool) ([][]Par
 	// 	f.Close()
 	// }()
 
-	return o, c, pxr
+	return o, c, pxr, term
 }
@@ -0,0 +1,40 @@
+package ptrack
+
+import (
+	"math"
+	"testing"
+)
+
+func TestCentroidalSoluteDecaysDownstream(t *testing.T) {
+	d := rowDomain([]float64{1., 1., 1., 1., 1., 1.}, nil)
+	if err := d.MakeVector(); err != nil {
+		t.Fatal(err)
+	}
+	sol := Solute{R: 2., Lambda: .1}
+	sol.SetPrismDecay(map[int]float64{1: 1.})
+	if err := d.SetSolute(&sol); err != nil {
+		t.Fatal(err)
+	}
+	excl := map[int]bool{0: true, 1: true, 3: true, 4: true} // centroid of prism 2 only
+	o, _, _, _ := d.TrackCentroidalParticles(excl, TrackOptions{}, false)
+	pl := o[0]
+
+	if pl[0].Cr != 1. {
+		t.Errorf("relative concentration at the first vertex: got %g, want 1", pl[0].Cr)
+	}
+	for k := 1; k < len(pl); k++ {
+		if pl[k].Cr > pl[k-1].Cr || pl[k].Tr < pl[k-1].Tr {
+			t.Fatalf("vertex %d: concentration rising (%g to %g) or retarded time falling (%g to %g) downstream", k, pl[k-1].Cr, pl[k].Cr, pl[k-1].Tr, pl[k].Tr)
+		}
+		if pl[k].T == 0. && pl[k].Tr != 0. {
+			t.Errorf("retarded time at the centroid: got %g, want 0", pl[k].Tr)
+		}
+	}
+
+	// each prism is crossed in .3 (porosity .3, unit flux and area); the pathline runs from entering prism 0 (x=1)
+	// to entering prism 4 (x=4, both offset slightly by the exit tolerance), crossing prism 1 decaying at 1, and prisms 2 and 3 at .1
+	want := math.Exp(-sol.R * (1.*.3 + .1*2.*.3))
+	if got := pl[len(pl)-1].Cr; math.Abs(got-want) > 1e-5 {
+		t.Errorf("relative concentration at the last vertex: got %g, want %g", got, want)
+	}
+}
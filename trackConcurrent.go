@@ -0,0 +1,44 @@
+package ptrack
+
+import (
+	"runtime"
+	"sync"
+)
+
+// trackBatch tracks particles ps, released from prisms pids, subject to opt, using a pool of nwrkrs workers.
+// Pathlines are returned in input order, in the domain's coordinate system (see SetCoordinates), along with the total number of vertices and their terminations.
+// The velocity field must be built beforehand, the Domain is only read from during tracking.
+func (d *Domain) trackBatch(ps []*Particle, pids []int, opt TrackOptions, nwrkrs int, prnt bool) ([][]Particle, int, []Termination) {
+	if nwrkrs <= 0 {
+		nwrkrs = runtime.GOMAXPROCS(0)
+	}
+	if nwrkrs > len(ps) {
+		nwrkrs = len(ps)
+	}
+
+	o, term := make([][]Particle, len(ps)), make([]Termination, len(ps))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for range nwrkrs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			t := d.newTracker(opt, prnt) // per-worker state
+			for k := range jobs {
+				o[k], term[k] = t.trackParticle(k, ps[k], pids[k])
+			}
+		}()
+	}
+	for k := range ps {
+		jobs <- k
+	}
+	close(jobs)
+	wg.Wait()
+	d.pathlinesFromModel(o, term)
+
+	c := 0
+	for _, pl := range o {
+		c += len(pl)
+	}
+	return o, c, term
+}
@@ -0,0 +1,36 @@
+package ptrack
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestTrackParticlesConcurrentMatchesSerial(t *testing.T) {
+	d := rowDomain([]float64{1., 1., 1.5, 1.5, 1.5, 1.5}, map[int]float64{1: .5})
+	if err := d.MakeVector(); err != nil {
+		t.Fatal(err)
+	}
+	var ps Particles
+	for k := range 20 {
+		ps = append(ps, Particle{I: 100 - k, X: .1 + float64(k)*.2, Y: .05 + float64(k%10)*.1, Z: .5})
+	}
+	pls, ns, terms, err := d.TrackParticles(ps, TrackOptions{}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	plc, nc, termc, err := d.TrackParticlesConcurrent(ps, TrackOptions{}, 4, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ns != nc {
+		t.Errorf("%d vertices tracked concurrently, %d serially", nc, ns)
+	}
+	for k := range ps {
+		if plc[k][0].I != ps[k].I {
+			t.Errorf("pathline %d holds particle %d, want input order (%d)", k, plc[k][0].I, ps[k].I)
+		}
+		if !slices.Equal(plc[k], pls[k]) || termc[k] != terms[k] {
+			t.Errorf("particle %d: concurrent pathline (%s) differs from serial (%s)", k, termc[k], terms[k])
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package ptrack
+
+import (
+	"math"
+	"sort"
+)
+
+// TrackOptions sets the stop criteria of particle tracking. Times are tracking (travel) times,
+// measured from the particle's release. Zero values imply no limit.
+type TrackOptions struct {
+	MaxTime     float64   // maximum tracking time
+	MaxVertices int       // maximum number of pathline vertices, random walks being limited to 100,000 when not set
+	StopTimes   []float64 // (optional) tracking times at which a vertex is recorded exactly, see PathlinesAt
+}
+
+// stops returns the sorted, positive stop times
+func (o *TrackOptions) stops() []float64 {
+	s := make([]float64, 0, len(o.StopTimes))
+	for _, t := range o.StopTimes {
+		if t > 0. {
+			s = append(s, t)
+		}
+	}
+	sort.Float64s(s)
+	return s
+}
+
+// timeLimit returns the particle time at which tracking stops, for a particle released at time t0
+func (o *TrackOptions) timeLimit(t0 float64) float64 {
+	if o.MaxTime > 0. {
+		return t0 + o.MaxTime
+	}
+	return math.Inf(1)
+}
+
+// PathlinesAt returns the pathlines cut at tracking time t (e.g., a 2-year time-of-travel),
+// as measured from each pathline's first vertex. When t is one of TrackOptions.StopTimes,
+// the last vertex of every pathline still active at t is located exactly at t.
+func PathlinesAt(pl [][]Particle, t float64) [][]Particle {
+	o := make([][]Particle, len(pl))
+	for k, a := range pl {
+		if len(a) == 0 {
+			continue
+		}
+		tc, n := a[0].T+t, len(a)
+		for j, p := range a {
+			if p.T > tc {
+				n = j
+				break
+			}
+		}
+		o[k] = a[:n]
+	}
+	return o
+}
@@ -0,0 +1,44 @@
+package ptrack
+
+import (
+	"math"
+	"testing"
+)
+
+func TestStopTimesInterpolated(t *testing.T) {
+	d := rowDomain([]float64{1., 1., 1., 1., 1., 1.}, nil)
+	if err := d.MakeVector(); err != nil {
+		t.Fatal(err)
+	}
+	opt := TrackOptions{MaxTime: .7, StopTimes: []float64{.5, .2}}
+	pl, _, term, err := d.TrackParticles(Particles{{X: .5, Y: .5, Z: .5, T: 1.}}, opt, false) // released at t=1
+	if err != nil {
+		t.Fatal(err)
+	}
+	if term[0].Status != TermMaxTime {
+		t.Fatalf("particle %s, want to reach the maximum time", term[0])
+	}
+
+	x := func(tt float64) float64 { return .5 + tt/.3 } // prisms are crossed in .3
+	at := func(tt float64) (Particle, bool) {
+		for _, p := range pl[0] {
+			if p.T == 1.+tt {
+				return p, true
+			}
+		}
+		return Particle{}, false
+	}
+	for _, tt := range []float64{.2, .5, .7} {
+		if p, ok := at(tt); !ok || math.Abs(p.X-x(tt)) > 1e-9 {
+			t.Errorf("tracking time %g: got vertex %+v, want one at x=%g", tt, p, x(tt))
+		}
+	}
+	if p := pl[0][len(pl[0])-1]; p.T != 1.7 {
+		t.Errorf("last vertex at t=%g, want the maximum time (1.7)", p.T)
+	}
+
+	cut := PathlinesAt(pl, .5)
+	if p := cut[0][len(cut[0])-1]; p.T != 1.5 || math.Abs(p.X-x(.5)) > 1e-9 {
+		t.Errorf("pathline cut at .5 ends at %+v, want the stop time vertex", p)
+	}
+}
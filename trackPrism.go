@@ -0,0 +1,273 @@
+package ptrack
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/maseology/mmaths/vector"
+)
+
+const ncheck, xuniq, prcsn = 1000, 10, .01
+
+const rwMaxVertices = 100000 // vertices of a random walk pathline when TrackOptions.MaxVertices is not set
+
+type pathline []Particle
+
+// tracker holds the state of the particle currently being tracked. Each
+// worker owns its own tracker such that the Domain is only ever read from.
+type tracker struct {
+	d    *Domain
+	pt   ParticleTracker // tracker used within the current prism
+	wpt  ParticleTracker // worker copy of the domain's tracker (Waterloo method)
+	rw   *RandomWalk     // particle copy of the domain's random walk, nil when purely advective
+	cycl map[int]int     // number of visits per prism
+	opt  TrackOptions    // stop criteria
+	stps []float64       // sorted stop times
+	prnt bool
+}
+
+func (d *Domain) newTracker(opt TrackOptions, prnt bool) *tracker {
+	return &tracker{d: d, opt: opt, stps: opt.stops(), prnt: prnt}
+}
+
+// vertexLimit returns the maximum number of pathline vertices, zero for no limit. Random walks, revisiting prisms
+// without a cycle being detected, are always limited.
+func (t *tracker) vertexLimit() int {
+	if t.rw != nil && t.opt.MaxVertices <= 0 {
+		return rwMaxVertices
+	}
+	return t.opt.MaxVertices
+}
+
+// maxVertices cuts the pathline at the maximum number of vertices, returning true if reached
+func (t *tracker) maxVertices(p *Particle, pl *pathline) bool {
+	n := t.vertexLimit()
+	if n <= 0 || len(*pl) < n {
+		return false
+	}
+	*pl = (*pl)[:n]
+	*p = (*pl)[n-1]
+	if t.prnt {
+		fmt.Printf("\tparticle has reached the maximum number of vertices at prism %d (%6.3f,%6.3f,%6.3f,%6.3e)\n", p.C, p.X, p.Y, p.Z, p.T)
+	}
+	return true
+}
+
+// trackPrisms tracks particle p from prism to prism, starting in prism i, until it terminates.
+// In transient domains, the flow field is switched as the particle crosses time step boundaries.
+func (t *tracker) trackPrisms(p *Particle, pl *pathline, i int) Termination {
+	d, prnt := t.d, t.prnt
+
+	// check for cycles
+	unique := func(s []complex128) []complex128 {
+		keys := make(map[complex128]bool)
+		list := []complex128{}
+		for _, ss := range s {
+			ss = complex(math.Round(real(ss)/prcsn)*prcsn, math.Round(imag(ss)/prcsn)*prcsn)
+			if _, ok := keys[ss]; !ok {
+				keys[ss] = true
+				list = append(list, ss)
+			}
+		}
+		return list
+	}
+	xy := make([]complex128, ncheck)
+
+	s, tx := d.stepAt(p.T) // time step, and the particle time at which it ends
+	vf, prsms, zw := d.stepField(s)
+	if t.rw != nil {
+		t.rw.vf, t.rw.prsms = vf, prsms
+	}
+
+	// stop criteria
+	tm, ks := t.opt.timeLimit(p.T), 0
+	tsnap := func(t0 float64) float64 {
+		if ks < len(t.stps) {
+			return t0 + t.stps[ks]
+		}
+		return math.Inf(1)
+	}
+	t0 := p.T
+	ts := tsnap(t0)
+
+	t.cycl = map[int]int{}
+	il := -1         // previous prism
+	stay := false    // particle remains in prism i, having reached a time limit
+	released := true // particle is in its release prism
+	for {
+		if p.T >= tx { // time step boundary reached
+			s, tx = d.nextStep(s)
+			vf, prsms, zw = d.stepField(s)
+			if t.rw != nil {
+				t.rw.vf, t.rw.prsms = vf, prsms
+			}
+			clear(t.cycl) // prisms may be revisited under a new flow field
+			if stay {
+				t.cycl[i]++
+			}
+			il = -1
+			if prnt {
+				fmt.Printf("\tswitching to KPER %d KSTP %d at prism %d (%6.3f,%6.3f,%6.3f,%6.3e)\n", d.steps[s].KPER, d.steps[s].KSTP, i, p.X, p.Y, p.Z, p.T)
+			}
+		}
+		for p.T >= ts { // snapshot reached
+			ks++
+			ts = tsnap(t0)
+		}
+
+		if !stay && t.rw == nil { // random walks will revisit prisms
+			t.cycl[i]++
+			if t.cycl[i] > 1 {
+				if prnt {
+					fmt.Printf("\ttracking aborted where particle cycle occurred at cell %d (%6.3f,%6.3f,%6.3f,%6.3e)\n", i, p.X, p.Y, p.Z, p.T)
+				}
+				return Termination{Status: TermCycle, Prism: i, Face: -1}
+			}
+		}
+		if !stay || (*pl)[len(*pl)-1].T != p.T {
+			p.C = i
+			p.Zone = d.zone[i]
+			*pl = append(*pl, *p)
+		}
+		if !stay && !released && d.stopzn[p.Zone] {
+			if prnt {
+				fmt.Printf("\tparticle has entered terminating zone %d at prism %d (%6.3f,%6.3f,%6.3f,%6.3e)\n", p.Zone, i, p.X, p.Y, p.Z, p.T)
+			}
+			return Termination{Status: TermZone, Prism: i, Face: -1}
+		}
+		stay, released = false, false
+
+		if p.T >= tm {
+			if prnt {
+				fmt.Printf("\tparticle has reached the maximum tracking time at prism %d (%6.3f,%6.3f,%6.3f,%6.3e)\n", i, p.X, p.Y, p.Z, p.T)
+			}
+			return Termination{Status: TermMaxTime, Prism: i, Face: -1}
+		}
+		if t.maxVertices(p, pl) {
+			return Termination{Status: TermMaxVertices, Prism: p.C, Face: -1}
+		}
+
+		if len(*pl) > ncheck && t.rw == nil {
+			lpl := len(*pl)
+			a := (*pl)[lpl-ncheck : lpl]
+			for i, aa := range a {
+				xy[i] = complex(aa.X, aa.Y)
+			}
+			uxy := unique(xy)
+
+			if len(uxy) <= xuniq {
+				if prnt {
+					fmt.Printf("\tparticle has exited domain at prism %d (%6.3f,%6.3f,%6.3f,%6.3e)\n\t** WARNING: cycle found involving %d prisms **\n", i, p.X, p.Y, p.Z, p.T, len(uxy))
+				}
+				return Termination{Status: TermCycle, Prism: i, Face: -1}
+			}
+		}
+
+		// check for well
+		switch vf[i].(type) {
+		case *PollockMethod:
+			t.pt = vf[i].(*PollockMethod) // type assertion, analytical solution (no tracking needed)
+			if len(zw[i]) > 0 && !d.isrev {
+				if stop, st := d.stopAtSink(s, i); stop {
+					if prnt {
+						fmt.Printf("\tparticle has exited domain at BC prism %d (%6.3f,%6.3f,%6.3f,%6.3e) (%s)\n", i, p.X, p.Y, p.Z, p.T, st)
+					}
+					return Termination{Status: st, Prism: i, Face: -1, Sink: sinkAt(zw[i], math.NaN(), math.NaN(), d.isrev)}
+				}
+			}
+		case *VectorMethSoln:
+			t.pt = vf[i].(*VectorMethSoln) // type assertion, geometrical solution (no tracking needed)
+			if len(zw[i]) > 0 {
+				if stop, st := d.stopAtSink(s, i); stop {
+					if prnt {
+						fmt.Printf("\tparticle has exited domain at BC prism %d (%6.3f,%6.3f,%6.3f,%6.3e) (%s)\n", i, p.X, p.Y, p.Z, p.T, st)
+					}
+					return Termination{Status: st, Prism: i, Face: -1, Sink: sinkAt(zw[i], math.NaN(), math.NaN(), d.isrev)}
+				}
+			}
+		default:
+			t.pt = t.wpt
+			wm := vf[i].(*WatMethSoln)
+			if k := wm.nearWell(p); k >= 0 { // wells are solved internal to the prism
+				if prnt {
+					fmt.Printf("\tparticle has exited by well %d at prism %d (%6.3f,%6.3f,%6.3f,%6.3e)\n", k, i, p.X, p.Y, p.Z, p.T)
+				}
+				return Termination{Status: TermWell, Prism: i, Face: -1, Sink: sinkAt(zw[i], p.X, p.Y, d.isrev)}
+			}
+		}
+
+		// track within prism
+		if t.rw != nil {
+			t.pt = t.rw
+		}
+		tl := min(tx, ts, tm) // time limit within prism
+		plt := trackToPrismExit(p, prsms[i], vf[i], t.pt, tl)
+		// // plt := pt.(*PollockMethod).TestTracktoExit(p, d.prsms[i], d.VF[i]) //  for testing (not concurrent)
+
+		if len(plt) > 2 {
+			*pl = append(*pl, plt...) // add tracks
+		}
+		if t.maxVertices(p, pl) {
+			return Termination{Status: TermMaxVertices, Prism: p.C, Face: -1}
+		}
+		if wm, ok := vf[i].(*WatMethSoln); ok {
+			if k := wm.nearWell(p); k >= 0 {
+				if prnt {
+					fmt.Printf("\tparticle has exited by well %d at prism %d (%6.3f,%6.3f,%6.3f,%6.3e)\n", k, i, p.X, p.Y, p.Z, p.T)
+				}
+				return Termination{Status: TermWell, Prism: i, Face: -1, Sink: sinkAt(zw[i], p.X, p.Y, d.isrev)}
+			}
+		}
+		if p.T >= tl && d.prsms[i].Contains(p) {
+			stay = true
+			continue // remains in prism i, having reached a time step boundary, snapshot or the maximum tracking time
+		}
+
+		// move to next prism
+		pids := d.ParticleToPrismIDs(p, i)
+		// fmt.Println(i, pids, p.X, p.Y, p.Z, p.T)
+		switch len(pids) {
+		case 0:
+			if prnt {
+				fmt.Printf("\tparticle has exited domain at prism %d\n", i)
+			}
+			return Termination{Status: TermDomainExit, Prism: i, Face: prsms[i].exitFace(p)}
+		case 1:
+			if pids[0] == il && t.rw == nil {
+				if prnt {
+					fmt.Printf("\ttracking aborted where particle cycle occurred between cells %d-%d (%6.3f,%6.3f,%6.3f,%6.3e)\n", i, il, p.X, p.Y, p.Z, p.T)
+				}
+				return Termination{Status: TermCycle, Prism: i, Face: -1}
+			} else if pids[0] == i {
+				if prnt {
+					fmt.Printf("\tparticle has exited top of water table at prism %d\n", i)
+				}
+				return Termination{Status: TermWaterTable, Prism: i, Face: prsms[i].exitFace(p)}
+			}
+			il, i = i, pids[0]
+		default:
+			if prnt {
+				fmt.Println(" particle likely at edge/vertex")
+			}
+			// selecting based on closest centroid-to-centroid trajectory
+			dsv, isv := math.MaxFloat64, -1
+			x0, y0, z0 := d.prsms[i].Centroid()
+			pxyz := [3]float64{p.X, p.Y, p.Z}
+			for i, pid := range pids {
+				x1, y1, z1 := d.prsms[pid].Centroid()
+				d, _, _ := vector.PointToLine(pxyz, [3]float64{x0, y0, z0}, [3]float64{x1, y1, z1})
+				if d < dsv {
+					dsv = d
+					isv = i
+				}
+			}
+			if isv < 0 {
+				if prnt {
+					fmt.Printf("\ttracking aborted where particle could not be resolved at edge/vertex of cell %d (%6.3f,%6.3f,%6.3f,%6.3e)\n", i, p.X, p.Y, p.Z, p.T)
+				}
+				return Termination{Status: TermEdge, Prism: i, Face: prsms[i].exitFace(p)}
+			}
+			il, i = i, pids[isv]
+		}
+	}
+}
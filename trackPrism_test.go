@@ -0,0 +1,57 @@
+package ptrack
+
+import (
+	"math"
+	"slices"
+	"testing"
+)
+
+// trackRecurse is the former recursive prism-to-prism tracking, reduced to particles that pass from prism to prism
+// until exiting the domain
+func trackRecurse(d *Domain, p *Particle, pl *pathline, i, il int) {
+	p.C = i
+	p.Zone = d.zone[i]
+	*pl = append(*pl, *p)
+	plt := trackToPrismExit(p, d.prsms[i], d.VF[i], d.VF[i].(ParticleTracker), math.Inf(1))
+	if len(plt) > 2 {
+		*pl = append(*pl, plt...)
+	}
+	if pids := d.ParticleToPrismIDs(p, i); len(pids) == 1 && pids[0] != i && pids[0] != il {
+		trackRecurse(d, p, pl, pids[0], i)
+	}
+}
+
+func TestTrackPrismsMatchesRecursion(t *testing.T) {
+	qx := []float64{1., 1., 1.2, 1.2, 1.5, 1.5, 1.5, 2., 2., 2.}
+	d := rowDomain(qx, map[int]float64{1: .2, 3: .3, 6: .5})
+	if err := d.MakeVector(); err != nil {
+		t.Fatal(err)
+	}
+	p0 := Particle{I: 1, X: .25, Y: .4, Z: .6}
+	pl, _, term, err := d.TrackParticles(Particles{p0}, TrackOptions{}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if term[0].Status != TermDomainExit || term[0].Prism != len(qx)-2 {
+		t.Fatalf("particle %s, want to exit the domain at the last prism", term[0])
+	}
+
+	var want pathline
+	pid, err := d.Locate(p0.X, p0.Y, p0.Z)
+	if err != nil {
+		t.Fatal(err)
+	}
+	trackRecurse(d, &p0, &want, pid, -1)
+	if !slices.Equal(pl[0], want) {
+		t.Errorf("pathline of %d vertices differs from the recursive pathline of %d vertices", len(pl[0]), len(want))
+	}
+	var cs []int
+	for _, p := range pl[0] {
+		if len(cs) == 0 || cs[len(cs)-1] != p.C {
+			cs = append(cs, p.C)
+		}
+	}
+	if !slices.Equal(cs, []int{0, 1, 2, 3, 4, 5, 6, 7, 8}) {
+		t.Errorf("prisms visited %v, want every prism of the row in order", cs)
+	}
+}
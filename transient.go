@@ -0,0 +1,216 @@
+package ptrack
+
+import (
+	"fmt"
+	"math"
+	"slices"
+)
+
+// TimeStep is a single stress period/time step of a transient flow field, spanning simulation times (T0, T1].
+// Fluxes are held constant over the time step, while saturated thickness varies linearly from the
+// end of the previous time step to the heads given at T1.
+type TimeStep struct {
+	KPER, KSTP int                             // stress period and time step (1-based, as reported by MODFLOW)
+	T0, T1     float64                         // simulation time at the start and end of the time step (TOTIM)
+	VF         map[int]VelocityFielder         // prism velocity field
+	prsms      map[int]*Prism                  // prism copies holding the time-varying saturated thickness
+	flx        map[int][]float64               // prism flux
+	shrd       map[int]map[int][]faceNeighbour // (optional) faces shared with several prisms, by prism and face
+	zw         map[int][]Well                  // point sources/sinks
+	qw         map[int]float64                 // total point flux
+	sto        bool                            // storage fluxes have been set (see SetStorage)
+}
+
+// TimeSteps returns the time steps of a transient domain, nil when steady-state
+func (d *Domain) TimeSteps() []*TimeStep { return d.steps }
+
+// IsTransient returns true if the domain holds more than one flow field
+func (d *Domain) IsTransient() bool { return len(d.steps) > 0 }
+
+// AddTimeStep appends a time step ending at simulation time totim to the domain, making it transient.
+// Time steps must be added in order, after New and before building the velocity field.
+// heads (optional) set the saturated thickness at totim; prisms without a head carry over the previous time step.
+// Storage fluxes are set separately, see SetStorage.
+func (d *Domain) AddTimeStep(kper, kstp int, totim float64, pflxs map[int][]float64, qwell, heads map[int]float64) error {
+	if d.isrev {
+		return fmt.Errorf("AddTimeStep: cannot add time steps to a reversed vector field")
+	}
+	t0, prev := 0., d.prsms
+	if n := len(d.steps); n > 0 {
+		t0, prev = d.steps[n-1].T1, d.steps[n-1].prsms
+		if totim <= t0 {
+			return fmt.Errorf("AddTimeStep: KPER %d KSTP %d ends at TOTIM=%g, before the previous time step (%g)", kper, kstp, totim, t0)
+		}
+	}
+
+	s := TimeStep{KPER: kper, KSTP: kstp, T0: t0, T1: totim, flx: pflxs}
+	s.zw, s.qw = wellsAt(d.prsms, qwell, nil)
+	s.prsms = make(map[int]*Prism, len(d.prsms))
+	for i, q := range d.prsms {
+		bn0 := prev[i].Bn + prev[i].Dbdt*(t0-prev[i].Tn) // saturated thickness at the end of the previous time step
+		if len(d.steps) == 0 {
+			bn0 = q.Bn
+		}
+		bn1 := bn0
+		if h, ok := heads[i]; ok {
+			bn1 = headToBn(q, h)
+			if len(d.steps) == 0 {
+				bn0 = bn1 // initial conditions are unknown, hold first time step constant
+			}
+		}
+		c := *q
+		c.Bn = bn0
+		c.Tn = t0
+		c.Dbdt = (bn1 - bn0) / (totim - t0)
+		c.Qss = 0.
+		s.prsms[i] = &c
+	}
+	d.steps = append(d.steps, &s)
+	return nil
+}
+
+// SetStorage sets the storage fluxes (positive released from storage) of the time step last added, closing the prism
+// water balance: specific storage qss is distributed over the prism, while specific yield qsy enters across the water
+// table (prism top) as it moves at Dbdt. Must be called once per time step, before building the velocity field.
+func (d *Domain) SetStorage(qss, qsy map[int]float64) error {
+	n := len(d.steps)
+	if n == 0 {
+		return fmt.Errorf("SetStorage: no time step added, see AddTimeStep")
+	}
+	s := d.steps[n-1]
+	if s.VF != nil {
+		return fmt.Errorf("SetStorage: KPER %d KSTP %d velocity field already built", s.KPER, s.KSTP)
+	}
+	if s.sto {
+		return fmt.Errorf("SetStorage: KPER %d KSTP %d storage already set", s.KPER, s.KSTP)
+	}
+	for i, v := range qss {
+		q, ok := s.prsms[i]
+		if !ok {
+			return fmt.Errorf("SetStorage: KPER %d KSTP %d specific storage given to unknown prism %d", s.KPER, s.KSTP, i)
+		}
+		q.Qss = v
+	}
+	for i := range qsy {
+		if _, ok := s.flx[i]; !ok {
+			return fmt.Errorf("SetStorage: KPER %d KSTP %d specific yield given to unknown prism %d", s.KPER, s.KSTP, i)
+		}
+	}
+	s.flx = specificYieldToTop(s.flx, qsy)
+	s.sto = true
+	return nil
+}
+
+// headToBn converts a simulated head to the prism's saturated thickness
+func headToBn(q *Prism, h float64) float64 {
+	if h < q.Top {
+		return math.Max(h, q.Bot)
+	}
+	return q.Top
+}
+
+// specificYieldToTop returns the prism fluxes with specific yield storage qsy added to the top face (the water table),
+// leaving pflxs unchanged
+func specificYieldToTop(pflxs map[int][]float64, qsy map[int]float64) map[int][]float64 {
+	if len(qsy) == 0 {
+		return pflxs
+	}
+	o := make(map[int][]float64, len(pflxs))
+	for i, f := range pflxs {
+		if v := qsy[i]; v != 0. && len(f) > 0 {
+			f = slices.Clone(f)
+			f[len(f)-1] += v // [laterals]-bottom-top
+		}
+		o[i] = f
+	}
+	return o
+}
+
+// buildVF builds the velocity field of the domain and, in transient cases, of every time step of duration ts (0 when steady-state)
+func (d *Domain) buildVF(build func(prsms map[int]*Prism, flx map[int][]float64, zw map[int][]Well, ts float64) (map[int]VelocityFielder, error)) error {
+	if len(d.steps) == 0 {
+		vf, err := build(d.prsms, d.flx, d.zw, 0.)
+		if err != nil {
+			return err
+		}
+		d.VF = vf
+		return nil
+	}
+	for _, s := range d.steps {
+		vf, err := build(s.prsms, s.flx, s.zw, s.T1-s.T0)
+		if err != nil {
+			return fmt.Errorf("KPER %d KSTP %d: %w", s.KPER, s.KSTP, err)
+		}
+		s.VF = vf
+	}
+	d.VF = d.steps[0].VF
+	return nil
+}
+
+// reverse reverses the time step's vector field, such that particle time runs backward from simulation time te
+func (s *TimeStep) reverse(te float64) {
+	for _, vf := range s.VF {
+		vf.ReverseVectorField()
+	}
+	dt := s.T1 - s.T0
+	for _, q := range s.prsms {
+		q.Bn += q.Dbdt * dt
+		q.Tn = te - q.Tn - dt
+		q.Dbdt *= -1.
+	}
+}
+
+// stepAt returns the time step active at particle time t, and the particle time at which it ends.
+// Returns -1 for steady-state domains. The first and last time steps extend indefinitely.
+func (d *Domain) stepAt(t float64) (int, float64) {
+	n := len(d.steps)
+	if n == 0 {
+		return -1, math.Inf(1)
+	}
+	if d.isrev {
+		s := n - 1
+		for s > 0 && t >= d.stepEnd(s) {
+			s--
+		}
+		return s, d.stepEnd(s)
+	}
+	s := 0
+	for s < n-1 && t >= d.stepEnd(s) {
+		s++
+	}
+	return s, d.stepEnd(s)
+}
+
+// nextStep returns the time step following s, in the direction of particle time
+func (d *Domain) nextStep(s int) (int, float64) {
+	if d.isrev {
+		s--
+	} else {
+		s++
+	}
+	return s, d.stepEnd(s)
+}
+
+// stepEnd returns the particle time at which time step s ends
+func (d *Domain) stepEnd(s int) float64 {
+	n := len(d.steps)
+	if d.isrev {
+		if s <= 0 {
+			return math.Inf(1)
+		}
+		return d.steps[n-1].T1 - d.steps[s].T0 // particle time runs backward from the end of the simulation
+	}
+	if s < 0 || s >= n-1 {
+		return math.Inf(1)
+	}
+	return d.steps[s].T1
+}
+
+// stepField returns the flow field of time step s, or that of the (steady-state) domain when s<0
+func (d *Domain) stepField(s int) (map[int]VelocityFielder, map[int]*Prism, map[int][]Well) {
+	if s < 0 {
+		return d.VF, d.prsms, d.zw
+	}
+	st := d.steps[s]
+	return st.VF, st.prsms, st.zw
+}
@@ -0,0 +1,97 @@
+package ptrack
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSetStorage(t *testing.T) {
+	d := rowDomain([]float64{1., 1., 1., 1.}, nil)
+	if err := d.SetStorage(map[int]float64{1: -.2}, nil); err == nil {
+		t.Error("expected an error setting storage before adding a time step")
+	}
+	if err := d.AddTimeStep(1, 1, 1., d.flx, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.SetStorage(map[int]float64{1: -.2}, map[int]float64{2: .1}); err != nil {
+		t.Fatal(err)
+	}
+	s := d.steps[0]
+	if s.prsms[1].Qss != -.2 || d.prsms[1].Qss != 0. {
+		t.Errorf("specific storage %g (domain prism %g), want -.2 held by the time step only", s.prsms[1].Qss, d.prsms[1].Qss)
+	}
+	if s.flx[2][5] != .1 || d.flx[2][5] != 0. {
+		t.Errorf("top flux %g (domain prism %g), want .1 added to the time step only", s.flx[2][5], d.flx[2][5])
+	}
+	if err := d.SetStorage(nil, map[int]float64{2: .1}); err == nil {
+		t.Error("expected an error setting storage twice")
+	}
+	if err := d.SetStorage(map[int]float64{9: 1.}, nil); err == nil {
+		t.Error("expected an error for an unknown prism")
+	}
+}
+
+func TestSinkFractionStorage(t *testing.T) {
+	d := rowDomain([]float64{1., 1., .5, .5}, nil)
+	for _, c := range []struct {
+		qss, want float64
+	}{
+		{0., .5},       // the well takes .5 of the inflow
+		{-.25, .75},    // the well and storage
+		{.5, .5 / 1.5}, // water released from storage adds to the inflow
+	} {
+		d.steps = nil
+		if err := d.AddTimeStep(1, 1, 1., d.flx, map[int]float64{1: -.5}, nil); err != nil { // well rate held, storage varied
+			t.Fatal(err)
+		}
+		if err := d.SetStorage(map[int]float64{1: c.qss}, nil); err != nil {
+			t.Fatal(err)
+		}
+		if got := d.sinkFraction(0, 1); math.Abs(got-c.want) > 1e-12 {
+			t.Errorf("storage %g: sink fraction %g, want %g", c.qss, got, c.want)
+		}
+	}
+}
+
+func TestTrackAcrossTimeSteps(t *testing.T) {
+	d := rowDomain([]float64{1., 1., 1., 1., 1., 1.}, nil)
+	fast := make(map[int][]float64, len(d.flx))
+	for i, f := range d.flx {
+		fast[i] = make([]float64, len(f))
+		for j, q := range f {
+			fast[i][j] = 2. * q
+		}
+	}
+	if err := d.AddTimeStep(1, 1, .3, d.flx, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.AddTimeStep(2, 1, 10., fast, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.MakeVector(); err != nil {
+		t.Fatal(err)
+	}
+	pl, _, term, err := d.TrackParticles(Particles{{X: .5, Y: .5, Z: .5}}, TrackOptions{}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if term[0].Status != TermDomainExit {
+		t.Fatalf("particle %s, want to exit the domain", term[0])
+	}
+
+	// prisms are crossed in .3 during the first time step, twice as fast thereafter
+	find := func(x float64) (Particle, bool) {
+		for _, p := range pl[0] {
+			if math.Abs(p.X-x) < 1e-4 {
+				return p, true
+			}
+		}
+		return Particle{}, false
+	}
+	if p, ok := find(1.5); !ok || math.Abs(p.T-.3) > 1e-9 || p.C != 1 {
+		t.Errorf("vertex %+v, want one at the time step boundary (t=.3) in prism 1", p)
+	}
+	if p, ok := find(2.); !ok || math.Abs(p.T-.375) > 1e-4 {
+		t.Errorf("vertex %+v, want prism 2 entered at t=.375", p)
+	}
+}
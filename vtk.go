@@ -4,14 +4,14 @@ import (
 	"bytes"
 	"encoding/binary"
 	"fmt"
-	"log"
+	"math"
 	"os"
 	"sort"
 	"time"
 )
 
 // ExportVTKpathlines saves particle tracking results as a *.vtk file for visualization.
-func ExportVTKpathlines(filepath string, pl [][][]float64, vertExag float64) {
+func ExportVTKpathlines(filepath string, pl [][][]float64, vertExag float64) error {
 	// write to data buffer
 	buf, endi, np := new(bytes.Buffer), binary.BigEndian, 0
 	for _, a := range pl {
@@ -49,14 +49,29 @@ func ExportVTKpathlines(filepath string, pl [][][]float64, vertExag float64) {
 
 	// write to file
 	if err := os.WriteFile(filepath, buf.Bytes(), 0644); err != nil {
-		log.Fatalf("os.WriteFile failed: %v", err)
+		return fmt.Errorf("ExportVTKpathlines: %w", err)
 	}
+	return nil
 }
 
-// ExportVTK saves model domain as a *.vtk file for visualization.
-func (d *Domain) ExportVTK(filepath string, vertExag float64) {
-	// collect cell ids, building flow field
+// ExportVTK saves model domain as a *.vtk file for visualization, in the domain's coordinate system.
+func (d *Domain) ExportVTK(filepath string, vertExag float64) error {
 	fmt.Println(" exporting VTK flow field..")
+	if err := d.writeVTK(filepath, vertExag, nil); err != nil {
+		return fmt.Errorf("ExportVTK: %w", err)
+	}
+	return nil
+}
+
+// vtkScalars is prism (cell) data appended to the domain export; prisms missing from V are written as NaN
+type vtkScalars struct {
+	Name string
+	V    map[int]float64
+}
+
+// writeVTK saves model domain, along with additional cell scalars, as a *.vtk file
+func (d *Domain) writeVTK(filepath string, vertExag float64, scalars []vtkScalars) error {
+	// collect cell ids, building flow field
 	nprsm, cids := func() (int, []int) {
 		cids, ii := make([]int, len(d.prsms)), 0
 		for i := range d.prsms {
@@ -74,12 +89,14 @@ func (d *Domain) ExportVTK(filepath string, vertExag float64) {
 		for _, i := range cids {
 			p, s1 := d.prsms[i], make([]int, 0)
 			for _, c := range p.Z {
-				v[cnt] = []float64{real(c), imag(c), p.Top * vertExag}
+				x, y := d.fromModel(real(c), imag(c))
+				v[cnt] = []float64{x, y, p.Top * vertExag}
 				s1 = append(s1, cnt)
 				cnt++
 			}
 			for _, c := range p.Z {
-				v[cnt] = []float64{real(c), imag(c), p.Bot * vertExag}
+				x, y := d.fromModel(real(c), imag(c))
+				v[cnt] = []float64{x, y, p.Bot * vertExag}
 				s1 = append(s1, cnt)
 				cnt++
 			}
@@ -116,7 +133,7 @@ func (d *Domain) ExportVTK(filepath string, vertExag float64) {
 	for _, i := range cids {
 		switch len(d.prsms[i].Z) {
 		case 0, 1, 2:
-			log.Fatalf("ExportVTK error: invalid prism shape")
+			return fmt.Errorf("invalid prism shape, prism %d", i)
 		case 3:
 			binary.Write(buf, endi, int32(13)) // VTK_WEDGE
 		case 4:
@@ -126,7 +143,7 @@ func (d *Domain) ExportVTK(filepath string, vertExag float64) {
 		case 6:
 			binary.Write(buf, endi, int32(16)) // VTK_HEXAGONAL_PRISM
 		default:
-			log.Fatalf("ExportVTK todo: >6 sided polyhedron")
+			return fmt.Errorf("todo: >6 sided polyhedron, prism %d", i)
 		}
 	}
 
@@ -146,21 +163,38 @@ func (d *Domain) ExportVTK(filepath string, vertExag float64) {
 	}
 
 	// centroid point velocity
-	binary.Write(buf, endi, []byte("\nVECTORS Vcentroid double\n"))
-	for _, i := range cids {
-		q := d.prsms[i]
-		x, y := q.CentroidXY()
-		p := Particle{I: 0, X: x, Y: y, Z: (q.Top + q.Bot) / 2., T: 0.}
-		vx, vy, vz := d.VF[i].PointVelocity(&p, q, 0.)
-		binary.Write(buf, endi, vx)
-		binary.Write(buf, endi, vy)
-		binary.Write(buf, endi, vz)
+	if len(d.VF) > 0 {
+		binary.Write(buf, endi, []byte("\nVECTORS Vcentroid double\n"))
+		for _, i := range cids {
+			q := d.prsms[i]
+			x, y := q.CentroidXY()
+			p := Particle{I: 0, X: x, Y: y, Z: (q.Top + q.Bot) / 2., T: 0.}
+			vx, vy, vz := d.VF[i].PointVelocity(&p, q, 0.)
+			if d.crs == WorldCoordinates {
+				v := complex(vx, vy) * d.xf.rotation() // rotated with the points
+				vx, vy = real(v), imag(v)
+			}
+			binary.Write(buf, endi, vx)
+			binary.Write(buf, endi, vy)
+			binary.Write(buf, endi, vz)
+		}
 	}
 
-	// write to file
-	if err := os.WriteFile(filepath, buf.Bytes(), 0644); err != nil {
-		log.Fatalf("os.WriteFile failed: %v", err)
+	// additional scalars
+	for _, sc := range scalars {
+		binary.Write(buf, endi, []byte(fmt.Sprintf("\nSCALARS %s float\n", sc.Name)))
+		binary.Write(buf, endi, []byte("LOOKUP_TABLE default\n"))
+		for _, i := range cids {
+			v, ok := sc.V[i]
+			if !ok {
+				v = math.NaN()
+			}
+			binary.Write(buf, endi, float32(v))
+		}
 	}
+
+	// write to file
+	return os.WriteFile(filepath, buf.Bytes(), 0644)
 }
 
 func vtkReorder(s []int) []int {
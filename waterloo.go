@@ -0,0 +1,203 @@
+package ptrack
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// WaterlooOptions sets how the Waterloo method solution of every prism is constructed
+type WaterlooOptions struct {
+	M, N int     // (fixed) number of control points and order of approximation, defaults to 80 and 30
+	Tol  float64 // (optional) target perimeter flux error; when set, the order is increased per prism until met
+	NMax int     // maximum order of approximation when Tol is set, defaults to 40
+
+	Workers  int                   // number of concurrent workers, defaults to GOMAXPROCS
+	Progress func(done, total int) // (optional) called as prisms are built, progress is printed by default
+}
+
+func (o WaterlooOptions) m() int {
+	if o.M <= 0 {
+		return 80
+	}
+	return o.M
+}
+
+func (o WaterlooOptions) n() int {
+	if o.N <= 0 {
+		return 30
+	}
+	return o.N
+}
+
+// SetWaterlooOptions sets the construction of the Waterloo method solutions, must be called before MakeWaterloo
+func (d *Domain) SetWaterlooOptions(o WaterlooOptions) error {
+	if o.M > 0 || o.N > 0 {
+		if o.m() < 2*o.n() {
+			return fmt.Errorf("SetWaterlooOptions: m < 2*n (m=%d, n=%d)", o.m(), o.n())
+		}
+	}
+	if o.Tol < 0. {
+		return fmt.Errorf("SetWaterlooOptions: negative tolerance %g", o.Tol)
+	}
+	if o.NMax <= 0 {
+		o.NMax = 40
+	}
+	d.wmopt = o
+	return nil
+}
+
+// buildWaterloo builds the Waterloo method solution of every prism using a pool of workers.
+// Prism errors are collected and returned together, sorted by prism ID.
+func (d *Domain) buildWaterloo(prsms map[int]*Prism, flx map[int][]float64, zw map[int][]Well) (map[int]VelocityFielder, error) {
+	o := d.wmopt
+	pids := make([]int, 0, len(prsms))
+	for i := range prsms {
+		pids = append(pids, i)
+	}
+	sort.Ints(pids)
+	nwrkrs := o.Workers
+	if nwrkrs <= 0 {
+		nwrkrs = runtime.GOMAXPROCS(0)
+	}
+	nwrkrs = max(1, min(nwrkrs, len(pids)))
+	progress := o.Progress
+	if progress == nil {
+		pct := 0
+		progress = func(done, total int) {
+			if p := 100 * done / total; p >= pct+10 || done == total {
+				pct = p
+				fmt.Printf("  %3d%% (%s/%s prisms)\n", p, big(done), big(total))
+			}
+		}
+	}
+
+	wms, errs := make([]*WatMethSoln, len(pids)), make([]error, len(pids))
+	jobs, done := make(chan int), make(chan struct{})
+	var wg sync.WaitGroup
+	for range nwrkrs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for k := range jobs {
+				i, q := pids[k], prsms[pids[k]]
+				var wm WatMethSoln
+				nf := len(flx[i])
+				if nf < 3 {
+					errs[k] = fmt.Errorf("prism %d has %d fluxes, expecting [laterals]-bottom-top", i, nf)
+				} else {
+					ql, qb, qt := flx[i][:nf-2], flx[i][nf-2], flx[i][nf-1] // [laterals CCW order]-bottom-top; left-up-right-down-bottom-top
+					if o.Tol > 0. {
+						errs[k] = wm.NewAdaptive(i, q, ql, zw[i], -qt, qb, o.Tol, o.NMax)
+					} else {
+						errs[k] = wm.New(i, q, ql, zw[i], -qt, qb, o.m(), o.n(), false)
+					}
+					wms[k] = &wm
+				}
+				done <- struct{}{}
+			}
+		}()
+	}
+	go func() {
+		for k := range pids {
+			jobs <- k
+		}
+		close(jobs)
+		wg.Wait()
+		close(done)
+	}()
+	n := 0
+	for range done { // progress is reported from a single goroutine
+		n++
+		progress(n, len(pids))
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	vf, nunmet := make(map[int]VelocityFielder, len(pids)), 0
+	for k, i := range pids {
+		vf[i] = wms[k]
+		if wms[k].unmet {
+			nunmet++
+		}
+	}
+	if nunmet > 0 {
+		fmt.Printf("  WARNING: %s prisms did not meet the perimeter flux error tolerance %g (see WaterlooAccuracy)\n", big(nunmet), o.Tol)
+	}
+	return vf, nil
+}
+
+// WaterlooAccuracy is the Waterloo method solution of a prism: number of control points,
+// order of approximation and the resulting perimeter flux error, relative to the largest face flux.
+// Met is false where the target error (WaterlooOptions.Tol) could not be reached, the most accurate solution being kept.
+type WaterlooAccuracy struct {
+	M, N int
+	Err  float64
+	Met  bool
+}
+
+// WaterlooAccuracy returns the accuracy of the Waterloo method solution of every prism
+// (of the steady-state, or first time step, flow field; see TimeStep.WaterlooAccuracy)
+func (d *Domain) WaterlooAccuracy() map[int]WaterlooAccuracy { return waterlooAccuracy(d.VF) }
+
+// WaterlooAccuracy returns the accuracy of the Waterloo method solution of every prism of the time step
+func (s *TimeStep) WaterlooAccuracy() map[int]WaterlooAccuracy { return waterlooAccuracy(s.VF) }
+
+func waterlooAccuracy(vf map[int]VelocityFielder) map[int]WaterlooAccuracy {
+	o := make(map[int]WaterlooAccuracy, len(vf))
+	for i, v := range vf {
+		if wm, ok := v.(*WatMethSoln); ok {
+			m, n, err := wm.Accuracy()
+			o[i] = WaterlooAccuracy{m, n, err, !wm.unmet}
+		}
+	}
+	return o
+}
+
+// ExportVTKwaterlooAccuracy saves model domain as a *.vtk file, with the Waterloo method accuracy as cell data
+func (d *Domain) ExportVTKwaterlooAccuracy(filepath string, vertExag float64) error {
+	fmt.Println(" exporting VTK Waterloo method accuracy..")
+	acc := d.WaterlooAccuracy()
+	sc := []vtkScalars{{"perimeterFluxError", make(map[int]float64, len(acc))}, {"order", make(map[int]float64, len(acc))}, {"controlPoints", make(map[int]float64, len(acc))}}
+	for i, a := range acc {
+		sc[0].V[i], sc[1].V[i], sc[2].V[i] = a.Err, float64(a.N), float64(a.M)
+	}
+	if err := d.writeVTK(filepath, vertExag, sc); err != nil {
+		return fmt.Errorf("ExportVTKwaterlooAccuracy: %w", err)
+	}
+	return nil
+}
+
+// SaveWaterlooAccuracyCSV saves the Waterloo method accuracy of every prism, and of every time step in transient cases
+func (d *Domain) SaveWaterlooAccuracyCSV(fp string) error {
+	var sb strings.Builder
+	write := func(pfx string, acc map[int]WaterlooAccuracy) {
+		pids := make([]int, 0, len(acc))
+		for i := range acc {
+			pids = append(pids, i)
+		}
+		sort.Ints(pids)
+		for _, i := range pids {
+			a := acc[i]
+			fmt.Fprintf(&sb, "%s%d,%d,%d,%g,%t\n", pfx, i, a.M, a.N, a.Err, a.Met)
+		}
+	}
+	if len(d.steps) == 0 {
+		sb.WriteString("pid,m,n,perimeterFluxError,met\n")
+		write("", d.WaterlooAccuracy())
+	} else {
+		sb.WriteString("kper,kstp,pid,m,n,perimeterFluxError,met\n")
+		for _, s := range d.steps {
+			write(fmt.Sprintf("%d,%d,", s.KPER, s.KSTP), s.WaterlooAccuracy())
+		}
+	}
+	if err := os.WriteFile(fp, []byte(sb.String()), 0644); err != nil {
+		return fmt.Errorf("SaveWaterlooAccuracyCSV: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package ptrack
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWaterlooAdaptiveFlagsUnmetTolerance(t *testing.T) {
+	var p Prism
+	if err := p.New([]complex128{0, 10i, 10 + 10i, 10}, 1., 0., 1., 0., .3); err != nil {
+		t.Fatal(err)
+	}
+	qj, zw := []float64{1., 0., 0., 0.}, []Well{{X: 4., Y: 6., Q: -1.}}
+	for _, c := range []struct {
+		tol float64
+		met bool
+	}{{.5, true}, {1e-12, false}} {
+		var w WatMethSoln
+		if err := w.NewAdaptive(0, &p, qj, zw, 0., 0., c.tol, 10); err != nil {
+			t.Fatal(err)
+		}
+		if a := waterlooAccuracy(map[int]VelocityFielder{0: &w}); a[0].Met != c.met {
+			t.Errorf("tol %g: met %t (error %g), want %t", c.tol, a[0].Met, a[0].Err, c.met)
+		}
+	}
+}
+
+func TestWaterlooAccuracyPerTimeStep(t *testing.T) {
+	d := rowDomain([]float64{1., 1., 1., 1.}, nil)
+	for k := range 2 {
+		if err := d.AddTimeStep(1, k+1, float64(k+1), d.flx, nil, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := d.SetWaterlooOptions(WaterlooOptions{M: 20, N: 5, Workers: 1, Progress: func(int, int) {}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.MakeWaterloo(&RungeKutta{Dt: .01}); err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range d.TimeSteps() {
+		if a := s.WaterlooAccuracy(); len(a) != 3 || !a[0].Met {
+			t.Errorf("KSTP %d: accuracy %v, want 3 prisms meeting tolerance", s.KSTP, a)
+		}
+	}
+
+	fp := filepath.Join(t.TempDir(), "acc.csv")
+	if err := d.SaveWaterlooAccuracyCSV(fp); err != nil {
+		t.Fatal(err)
+	}
+	b, err := os.ReadFile(fp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ln := strings.Split(strings.TrimSpace(string(b)), "\n")
+	if len(ln) != 1+2*3 || !strings.HasPrefix(ln[0], "kper,kstp,") || !strings.HasPrefix(ln[4], "1,2,0,") {
+		t.Errorf("unexpected accuracy csv:\n%s", b)
+	}
+	if err := d.SaveWaterlooAccuracyCSV(filepath.Join(fp, "x.csv")); err == nil {
+		t.Error("expected an error saving to an invalid path")
+	}
+}
+
+func TestBuildWaterlooConcurrent(t *testing.T) {
+	d := rowDomain([]float64{1., 1., .8, .8, .8, .5, .5}, map[int]float64{1: -.2, 4: -.3})
+	build := func(nwrkrs int) (map[int]VelocityFielder, error) {
+		n := 0
+		if err := d.SetWaterlooOptions(WaterlooOptions{M: 20, N: 5, Workers: nwrkrs, Progress: func(done, total int) { n = done }}); err != nil {
+			t.Fatal(err)
+		}
+		vf, err := d.buildWaterloo(d.prsms, d.flx, d.zw)
+		if err == nil && n != len(d.prsms) {
+			t.Errorf("%d workers: progress reported %d of %d prisms", nwrkrs, n, len(d.prsms))
+		}
+		return vf, err
+	}
+	vs, err := build(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	vc, err := build(4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(vs, vc) {
+		t.Error("concurrent build differs from the serial build")
+	}
+
+	d.flx[1], d.flx[4] = []float64{1.}, []float64{}
+	_, err = build(4)
+	if err == nil {
+		t.Fatal("expected an error for prisms missing fluxes")
+	}
+	if s := err.Error(); !strings.Contains(s, "prism 1 ") || !strings.Contains(s, "prism 4 ") || strings.Index(s, "prism 1 ") > strings.Index(s, "prism 4 ") {
+		t.Errorf("got %q, want both prism errors joined in prism order", s)
+	}
+}
@@ -0,0 +1,32 @@
+package ptrack
+
+import "testing"
+
+func TestSourcePrismDoesNotStop(t *testing.T) {
+	d := rowDomain([]float64{1., 1., 1.5, 1.5, 1.5}, map[int]float64{1: .5}) // injection in prism 1
+	if err := d.MakeVector(); err != nil {
+		t.Fatal(err)
+	}
+	_, _, term, err := d.TrackParticles(Particles{{X: .5, Y: .5, Z: .5}}, TrackOptions{}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if term[0].Status != TermDomainExit || term[0].Prism != 3 {
+		t.Errorf("particle passing an injection prism: %s, expecting to exit the domain at prism 3", term[0])
+	}
+}
+
+func TestReverseThroughPumpingPrism(t *testing.T) {
+	d := rowDomain([]float64{1., 1., .5, .5, .5}, map[int]float64{1: -.5}) // pumping in prism 1
+	if err := d.MakeVector(); err != nil {
+		t.Fatal(err)
+	}
+	d.ReverseVectorField()
+	_, _, term, err := d.TrackParticles(Particles{{X: 3.5, Y: .5, Z: .5}}, TrackOptions{}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if term[0].Status != TermDomainExit || term[0].Prism != 0 {
+		t.Errorf("particle reverse tracked through a pumping prism: %s, expecting to exit the domain at prism 0", term[0])
+	}
+}
@@ -0,0 +1,113 @@
+package ptrack
+
+import (
+	"fmt"
+	"math"
+)
+
+// Well is a point source/sink
+type Well struct {
+	X, Y, Z float64 // coordinates; Z locates the prism (screen elevation). NaN X,Y place the well at the prism centroid
+	Q       float64 // rate, positive in (injection), negative out (pumping)
+	Pkg     string  // (optional) source/sink type, i.e., the MODFLOW6 budget term: "WEL", "RIV", "DRN", etc.
+	Name    string  // (optional) package name, i.e., "RIV-1"
+	Bound   string  // (optional) boundary name (BOUNDNAMES), left empty by ReadMODFLOW as budget files hold none
+}
+
+// wellsAt returns the point sources/sinks of every prism and their total flux. Wells wls (optional) are given at their
+// coordinates; the remainder of the prism's point flux qwell (e.g., constant head boundaries) is placed at the prism centroid.
+func wellsAt(prsms map[int]*Prism, qwell map[int]float64, wls map[int][]Well) (map[int][]Well, map[int]float64) {
+	zw, qw := make(map[int][]Well, len(qwell)), make(map[int]float64, len(qwell))
+	for i, q := range prsms {
+		if qwell[i] == 0. && len(wls[i]) == 0 {
+			continue
+		}
+		x, y := q.CentroidXY()
+		z := (q.Top + q.Bot) / 2.
+		r, sq := qwell[i], 0.
+		for _, w := range wls[i] {
+			if math.IsNaN(w.X) || math.IsNaN(w.Y) {
+				w.X, w.Y = x, y
+			}
+			zw[i] = append(zw[i], w)
+			r -= w.Q
+			sq += math.Abs(w.Q)
+		}
+		if math.Abs(r) > mingtzero*math.Max(sq, math.Abs(qwell[i])) {
+			zw[i] = append(zw[i], Well{X: x, Y: y, Z: z, Q: r})
+		}
+		if qwell[i] != 0. {
+			qw[i] = qwell[i]
+		}
+	}
+	return zw, qw
+}
+
+// sinkAt returns the source/sink of wls capturing a particle at (x,y): the strongest sink, in the direction of tracking,
+// among those closest to the particle. NaN coordinates consider every source/sink (i.e., sinks distributed over the prism).
+// Returns nil when wls is empty.
+func sinkAt(wls []Well, x, y float64, rev bool) *Well {
+	sgn := -1. // sinks are negative
+	if rev {
+		sgn = 1.
+	}
+	kx, dx, qx := -1, math.MaxFloat64, -math.MaxFloat64
+	for k, w := range wls {
+		d := 0.
+		if !math.IsNaN(x) && !math.IsNaN(y) {
+			d = math.Round(math.Hypot(w.X-x, w.Y-y)/wellTol) * wellTol // wells within tolerance are considered coincident
+		}
+		if d < dx || (d == dx && sgn*w.Q > qx) {
+			kx, dx, qx = k, d, sgn*w.Q
+		}
+	}
+	if kx < 0 {
+		return nil
+	}
+	w := wls[kx]
+	return &w
+}
+
+// Wells returns the point sources/sinks of prism pid (of the steady-state, or first time step, flow field)
+func (d *Domain) Wells(pid int) []Well { return d.zw[pid] }
+
+// SetWells places wells at their coordinates (in the domain's coordinate system), the prism being located using the well's X, Y and Z.
+// The prism fluxes are unchanged: well rates are taken as part of the prism's point flux (e.g., as read from
+// the WEL package), the remainder being placed at the prism centroid. In transient domains, wells apply to every time step;
+// transient domains holding sources/sinks read per time step from budget terms (see ReadMODFLOW) are rejected, as their
+// names and time-varying rates would be lost. Must be called before building the velocity field.
+func (d *Domain) SetWells(ws []Well) error {
+	for _, s := range d.steps {
+		for _, zw := range s.zw {
+			for _, w := range zw {
+				if w.Pkg != "" {
+					return fmt.Errorf("SetWells: transient domain holds %s sources/sinks read at KPER %d KSTP %d, wells cannot be replaced", w.Pkg, s.KPER, s.KSTP)
+				}
+			}
+		}
+	}
+	wls := make(map[int][]Well)
+	for k, w := range ws {
+		w.X, w.Y = d.toModel(w.X, w.Y)
+		pid, err := d.locate(w.X, w.Y, w.Z)
+		if err != nil {
+			return fmt.Errorf("SetWells: well %d: %w", k, err)
+		}
+		wls[pid] = append(wls[pid], w)
+	}
+	d.setWells(wls, nil)
+	return nil
+}
+
+// setWells sets the point sources/sinks of the domain and, in transient cases, of every time step.
+// Time steps are given their own wells stwls when provided.
+func (d *Domain) setWells(wls map[int][]Well, stwls []map[int][]Well) {
+	d.zw, d.qw = wellsAt(d.prsms, d.qw, wls)
+	for k, s := range d.steps {
+		w := wls
+		if k < len(stwls) {
+			w = stwls[k]
+		}
+		s.zw, s.qw = wellsAt(d.prsms, s.qw, w)
+	}
+}
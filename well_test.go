@@ -0,0 +1,40 @@
+package ptrack
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSetWellsTransient(t *testing.T) {
+	qx := []float64{1., 1., .5, .5}
+	transient := func(budget bool) *Domain {
+		d := rowDomain(qx, map[int]float64{1: -.5})
+		for k := range 2 {
+			if err := d.AddTimeStep(1, k+1, float64(k+1), d.flx, d.qw, nil); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if budget {
+			stwls := []map[int][]Well{
+				{1: {{X: math.NaN(), Y: math.NaN(), Q: -.5, Pkg: "WEL", Name: "WEL-1"}}},
+				{1: {{X: math.NaN(), Y: math.NaN(), Q: -.5, Pkg: "WEL", Name: "WEL-1"}}},
+			}
+			d.setWells(stwls[0], stwls)
+		}
+		return d
+	}
+	ws := []Well{{X: 1.25, Y: .5, Z: .5, Q: -.5}}
+
+	if err := transient(true).SetWells(ws); err == nil {
+		t.Error("expected an error setting wells on a transient domain holding budget sinks")
+	}
+	d := transient(false)
+	if err := d.SetWells(ws); err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range d.steps {
+		if zw := s.zw[1]; len(zw) != 1 || zw[0].X != 1.25 {
+			t.Errorf("KSTP %d: got sinks %v, expecting the well alone", s.KSTP, zw)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package ptrack
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// SetZones assigns an integer zone to every prism, prisms not listed are given zone 0
+func (d *Domain) SetZones(zn map[int]int) {
+	d.zone = make(map[int]int, len(zn))
+	for i, z := range zn {
+		d.zone[i] = z
+	}
+}
+
+// SetStopZones sets the terminating zones: particles stop upon entering any prism of these zones
+func (d *Domain) SetStopZones(zns ...int) {
+	d.stopzn = make(map[int]bool, len(zns))
+	for _, z := range zns {
+		d.stopzn[z] = true
+	}
+}
+
+// Zone returns the zone of prism pid
+func (d *Domain) Zone(pid int) int { return d.zone[pid] }
+
+// IsStopZone returns true if zone z is a terminating zone
+func (d *Domain) IsStopZone(z int) bool { return d.stopzn[z] }
+
+// ReadZones reads prism zones from a text file of "prismID,zone" pairs, one per line.
+// Values may be separated by commas, tabs or spaces; lines that do not start with a prism ID (headers, comments) are skipped.
+func (d *Domain) ReadZones(fp string) error {
+	f, err := os.Open(fp)
+	if err != nil {
+		return fmt.Errorf("ReadZones: %w", err)
+	}
+	defer f.Close()
+
+	zn, ln := make(map[int]int), 0
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		ln++
+		sp := strings.FieldsFunc(scanner.Text(), func(r rune) bool { return r == ',' || r == ' ' || r == '\t' })
+		if len(sp) == 0 {
+			continue
+		}
+		pid, err := strconv.Atoi(sp[0])
+		if err != nil {
+			continue // header or comment
+		}
+		if len(sp) < 2 {
+			return fmt.Errorf("ReadZones %s line %d: zone not given for prism %d", fp, ln, pid)
+		}
+		z, err := strconv.Atoi(sp[1])
+		if err != nil {
+			return fmt.Errorf("ReadZones %s line %d: %w", fp, ln, err)
+		}
+		if _, ok := d.prsms[pid]; !ok {
+			return fmt.Errorf("ReadZones %s line %d: prism %d not in domain", fp, ln, pid)
+		}
+		zn[pid] = z
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("ReadZones %s: %w", fp, err)
+	}
+	d.SetZones(zn)
+	return nil
+}
@@ -0,0 +1,41 @@
+package ptrack
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestStopZone(t *testing.T) {
+	d := rowDomain([]float64{1., 1., 1., 1., 1., 1.}, nil)
+	fp := filepath.Join(t.TempDir(), "zones.csv")
+	if err := os.WriteFile(fp, []byte("pid,zone\n0,4\n1 2\n3\t7\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.ReadZones(fp); err != nil {
+		t.Fatal(err)
+	}
+	d.SetStopZones(4, 7)
+	if err := d.MakeVector(); err != nil {
+		t.Fatal(err)
+	}
+	pl, _, term, err := d.TrackParticles(Particles{{X: .5, Y: .5, Z: .5}}, TrackOptions{}, false) // released within stop zone 4
+	if err != nil {
+		t.Fatal(err)
+	}
+	if term[0].Status != TermZone || term[0].Prism != 3 {
+		t.Errorf("particle %s, want to stop upon entering zone 7 at prism 3", term[0])
+	}
+	for _, p := range pl[0] {
+		if p.Zone != d.Zone(p.C) {
+			t.Errorf("vertex in prism %d tagged zone %d, want %d", p.C, p.Zone, d.Zone(p.C))
+		}
+	}
+
+	if err := os.WriteFile(fp, []byte("9,1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.ReadZones(fp); err == nil {
+		t.Error("expected an error for a prism not in the domain")
+	}
+}